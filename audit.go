@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const auditActorAdmin = "admin"
+
+// recordAudit appends an entry to the audit log. details is stored as JSON.
+// Pass the transaction's queries so the entry commits with the change.
+func recordAudit(ctx context.Context, q *database.Queries, actor, action, subjectType, subjectID string, details any) error {
+	dat, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	return q.CreateAuditEntry(ctx, database.CreateAuditEntryParams{
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		Actor:       actor,
+		Action:      action,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Details:     string(dat),
+	})
+}
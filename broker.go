@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/broker"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const brokerPublishTimeout = 5 * time.Second
+
+// brokerForwarder returns an event handler that publishes every event to pub
+// under "<prefix>.<event type>".
+func brokerForwarder(pub broker.Publisher, prefix string) events.Handler {
+	return func(ctx context.Context, e events.Event) error {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("couldn't encode %s event for broker: %w", e.Type, err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, brokerPublishTimeout)
+		defer cancel()
+		if err := pub.Publish(ctx, prefix+"."+string(e.Type), payload); err != nil {
+			return fmt.Errorf("couldn't publish %s event to broker: %w", e.Type, err)
+		}
+		return nil
+	}
+}
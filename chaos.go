@@ -0,0 +1,97 @@
+//go:build chaos
+
+package main
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// chaosConfig sets how often faults are injected into requests that don't
+// ask for one with X-Chaos-* headers. Percentages are out of 100.
+type chaosConfig struct {
+	LatencyPercent int
+	Latency        time.Duration
+	ErrorPercent   int
+	DropPercent    int
+}
+
+// chaosMiddleware injects faults so clients and their retries can be tested
+// against realistic failures. It only exists in builds with the chaos tag,
+// and even then needs CHAOS=true. A request can ask for a fault directly:
+//
+//	X-Chaos-Latency-Ms: 500   delay the request
+//	X-Chaos-Error: 503        respond with this status instead
+//	X-Chaos-Drop: true        close the connection without responding
+func chaosMiddleware() func(http.Handler) http.Handler {
+	if !getEnvBool("CHAOS", false) {
+		return nil
+	}
+	cfg := chaosConfig{
+		LatencyPercent: getEnvInt("CHAOS_LATENCY_PERCENT", 0),
+		Latency:        time.Duration(getEnvInt("CHAOS_LATENCY_MS", 1000)) * time.Millisecond,
+		ErrorPercent:   getEnvInt("CHAOS_ERROR_PERCENT", 0),
+		DropPercent:    getEnvInt("CHAOS_DROP_PERCENT", 0),
+	}
+	log.Printf("Chaos enabled: %+v", cfg)
+
+	chance := func(percent int) bool { return percent > 0 && rand.Intn(100) < percent }
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Probes are left alone, so an orchestrator doesn't restart
+			// instances that are being tested.
+			if r.URL.Path == "/v1/healthz" || r.URL.Path == "/v1/version" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			latency := time.Duration(0)
+			if ms, err := strconv.Atoi(r.Header.Get("X-Chaos-Latency-Ms")); err == nil && ms > 0 {
+				latency = time.Duration(ms) * time.Millisecond
+			} else if chance(cfg.LatencyPercent) {
+				latency = cfg.Latency
+			}
+			if latency > 0 {
+				select {
+				case <-time.After(latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			if r.Header.Get("X-Chaos-Drop") == "true" || chance(cfg.DropPercent) {
+				dropConnection(w)
+				return
+			}
+
+			code, err := strconv.Atoi(r.Header.Get("X-Chaos-Error"))
+			if err != nil || code < 400 || code > 599 {
+				code = 0
+				if chance(cfg.ErrorPercent) {
+					code = http.StatusInternalServerError
+				}
+			}
+			if code != 0 {
+				respondWithError(w, code, "injected_fault", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// dropConnection closes the client connection without writing a response.
+func dropConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// HTTP/2, or a writer that can't be hijacked; aborting the handler
+		// resets the stream instead.
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !chaos
+
+package main
+
+import "net/http"
+
+// chaosMiddleware is only available in builds with the chaos tag.
+func chaosMiddleware() func(http.Handler) http.Handler {
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/broker"
+	"github.com/bootdotdev/learn-cicd-starter/internal/moderation"
+)
+
+//go:embed sql/schema/*.sql
+var schemaFiles embed.FS
+
+type configCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type configReport struct {
+	OK     bool          `json:"ok"`
+	Checks []configCheck `json:"checks"`
+}
+
+func (r *configReport) add(name string, err error, detail string) {
+	check := configCheck{Name: name, OK: err == nil, Detail: detail}
+	if err != nil {
+		check.Detail = err.Error()
+		r.OK = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// runConfigCheck validates the configuration without starting the server and
+// writes a JSON report to w. It returns false if any check failed. Settings
+// are checked the way startup reads them, but nothing is opened except the
+// database connection.
+func runConfigCheck(w io.Writer, dbURL string) bool {
+	report := configReport{OK: true}
+
+	target, err := listenTarget()
+	report.add("listener", err, target)
+
+	internalPort := os.Getenv("INTERNAL_PORT")
+	switch {
+	case internalPort == "":
+		report.add("internal_port", nil, "not set, admin endpoints are served with the API")
+	case internalPort == os.Getenv("PORT"):
+		report.add("internal_port", errors.New("INTERNAL_PORT must differ from PORT"), "")
+	default:
+		_, err := net.LookupPort("tcp", internalPort)
+		report.add("internal_port", err, internalPort)
+	}
+
+	if kind := os.Getenv("MODERATION"); kind == "" {
+		report.add("moderation", nil, "not set, notes aren't moderated")
+	} else {
+		action, err := moderationActionFromEnv()
+		if err == nil {
+			_, err = moderation.New(kind, os.Getenv("MODERATION_SOURCE"))
+		}
+		report.add("moderation", err, kind+", "+action)
+	}
+
+	backend, err := metricsBackendFromEnv()
+	report.add("metrics", err, backend)
+
+	if kind := os.Getenv("BROKER"); kind == "" {
+		report.add("broker", nil, "not set, events aren't published")
+	} else {
+		pub, err := broker.New(kind, os.Getenv("BROKER_URL"))
+		if err == nil {
+			pub.Close()
+		}
+		report.add("broker", err, kind)
+	}
+
+	if dbURL == "" {
+		report.add("database", nil, "DATABASE_URL not set, running without CRUD endpoints")
+	} else {
+		checkDatabase(&report, dbURL)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	return report.OK
+}
+
+func checkDatabase(report *configReport, dbURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("libsql", dbURL)
+	if err != nil {
+		report.add("database", err, "")
+		return
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.add("database", err, "")
+		return
+	}
+	report.add("database", nil, "connected")
+
+	want, err := latestSchemaVersion()
+	if err != nil {
+		report.add("migrations", err, "")
+		return
+	}
+
+	// goose records applied migrations in its own table, which sqlc doesn't
+	// know about.
+	var have sql.NullInt64
+	err = db.QueryRowContext(ctx, "SELECT MAX(version_id) FROM goose_db_version WHERE is_applied").Scan(&have)
+	if err != nil {
+		report.add("migrations", fmt.Errorf("couldn't read migration status: %w", err), "")
+		return
+	}
+	if have.Int64 < want {
+		report.add("migrations", fmt.Errorf("database is at version %d, latest migration is %d", have.Int64, want), "")
+		return
+	}
+	report.add("migrations", nil, fmt.Sprintf("at version %d", have.Int64))
+}
+
+func latestSchemaVersion() (int64, error) {
+	entries, err := schemaFiles.ReadDir("sql/schema")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(path.Base(entry.Name()), "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad migration file name %s: %w", entry.Name(), err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestRunConfigCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		wantOK bool
+		failed string
+	}{
+		{
+			name:   "port",
+			env:    map[string]string{"PORT": "8080"},
+			wantOK: true,
+		},
+		{
+			name:   "no listener",
+			env:    map[string]string{},
+			failed: "listener",
+		},
+		{
+			name:   "unix socket without port",
+			env:    map[string]string{"LISTEN_SOCKET": "/run/notely.sock"},
+			wantOK: true,
+		},
+		{
+			name:   "bad socket mode",
+			env:    map[string]string{"LISTEN_SOCKET": "/run/notely.sock", "LISTEN_SOCKET_MODE": "rw"},
+			failed: "listener",
+		},
+		{
+			name:   "systemd socket without port",
+			env:    map[string]string{"LISTEN_PID": "self", "LISTEN_FDS": "1"},
+			wantOK: true,
+		},
+		{
+			name:   "internal port same as port",
+			env:    map[string]string{"PORT": "8080", "INTERNAL_PORT": "8080"},
+			failed: "internal_port",
+		},
+		{
+			name:   "bad internal port",
+			env:    map[string]string{"PORT": "8080", "INTERNAL_PORT": "not-a-port"},
+			failed: "internal_port",
+		},
+		{
+			name:   "bad moderation action",
+			env:    map[string]string{"PORT": "8080", "MODERATION": "http", "MODERATION_SOURCE": "http://localhost", "MODERATION_ACTION": "delete"},
+			failed: "moderation",
+		},
+		{
+			name:   "bad metrics backend",
+			env:    map[string]string{"PORT": "8080", "METRICS_BACKEND": "prometheus"},
+			failed: "metrics",
+		},
+		{
+			name:   "bad broker",
+			env:    map[string]string{"PORT": "8080", "BROKER": "carrier-pigeon"},
+			failed: "broker",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{
+				"PORT", "LISTEN_SOCKET", "LISTEN_SOCKET_MODE", "LISTEN_PID", "LISTEN_FDS", "INTERNAL_PORT",
+				"MODERATION", "MODERATION_SOURCE", "MODERATION_ACTION", "METRICS_BACKEND", "BROKER", "BROKER_URL",
+			} {
+				t.Setenv(name, "")
+			}
+			for name, value := range tt.env {
+				if value == "self" {
+					value = strconv.Itoa(os.Getpid())
+				}
+				t.Setenv(name, value)
+			}
+
+			var out bytes.Buffer
+			ok := runConfigCheck(&out, "")
+			var report configReport
+			if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || report.OK != tt.wantOK {
+				t.Fatalf("ok = %v, want %v: %+v", ok, tt.wantOK, report.Checks)
+			}
+			for _, check := range report.Checks {
+				if !check.OK && check.Name != tt.failed {
+					t.Errorf("check %s failed: %s", check.Name, check.Detail)
+				}
+				if check.Name == tt.failed && check.OK {
+					t.Errorf("check %s passed, want it to fail", check.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const minRevokePrefixLength = 8
+
+func runKeysCommand(ctx context.Context, db *database.Queries, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: notely keys <create|revoke> [options]")
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+		userID := fs.String("user", "", "ID of the user to issue a key for")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *userID == "" {
+			return errors.New("usage: notely keys create --user <id>")
+		}
+		return keysCreate(ctx, db, *userID)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: notely keys revoke <prefix>")
+		}
+		return keysRevoke(ctx, db, args[1])
+	default:
+		return fmt.Errorf("unknown keys command: %s", args[0])
+	}
+}
+
+// keysCreate issues a new API key for the user, replacing the old one, and
+// prints it. The key is not stored anywhere else, so this is the only chance
+// to copy it.
+func keysCreate(ctx context.Context, db *database.Queries, userID string) error {
+	apiKey, err := generateRandomSHA256Hash()
+	if err != nil {
+		return err
+	}
+
+	n, err := db.UpdateUserAPIKey(ctx, database.UpdateUserAPIKeyParams{
+		ApiKey:    apiKey,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		ID:        userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no user with id %s", userID)
+	}
+
+	fmt.Println(apiKey)
+	return nil
+}
+
+// keysRevoke invalidates the API key starting with prefix by overwriting it
+// with a random key that is never shown to anyone.
+func keysRevoke(ctx context.Context, db *database.Queries, prefix string) error {
+	if len(prefix) < minRevokePrefixLength {
+		return fmt.Errorf("prefix must be at least %d characters", minRevokePrefixLength)
+	}
+	if strings.Trim(prefix, "0123456789abcdef") != "" {
+		return errors.New("prefix must be lowercase hex")
+	}
+
+	users, err := db.GetUsersByAPIKeyPrefix(ctx, prefix+"%")
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("no api key starts with %s", prefix)
+	}
+	if len(users) > 1 {
+		return fmt.Errorf("prefix %s matches %d api keys, use a longer prefix", prefix, len(users))
+	}
+
+	replacement, err := generateRandomSHA256Hash()
+	if err != nil {
+		return err
+	}
+	_, err = db.UpdateUserAPIKey(ctx, database.UpdateUserAPIKeyParams{
+		ApiKey:    replacement,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		ID:        users[0].ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("revoked api key for user %s\n", users[0].ID)
+	return nil
+}
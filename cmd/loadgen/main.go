@@ -0,0 +1,254 @@
+// Command loadgen drives a mixed read/write workload against a running Notely
+// instance and reports latency percentiles per operation.
+//
+//	go run ./cmd/loadgen -url https://staging.example.com -duration 1m
+//
+// It creates its own users, so point it at staging, not production. It exits
+// with status 1 if the error rate is above -max-error-rate.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+type config struct {
+	baseURL      string
+	users        int
+	seedNotes    int
+	concurrency  int
+	duration     time.Duration
+	rate         float64
+	writeRatio   float64
+	pageSize     int
+	maxErrorRate float64
+}
+
+type result struct {
+	op      string
+	latency time.Duration
+	err     error
+}
+
+func main() {
+	cfg := config{}
+	flag.StringVar(&cfg.baseURL, "url", "http://localhost:8080", "base URL of the instance")
+	flag.IntVar(&cfg.users, "users", 10, "users to create before the run")
+	flag.IntVar(&cfg.seedNotes, "seed-notes", 20, "notes to create for each user before the run")
+	flag.IntVar(&cfg.concurrency, "concurrency", 20, "concurrent workers")
+	flag.DurationVar(&cfg.duration, "duration", 30*time.Second, "how long to run")
+	flag.Float64Var(&cfg.rate, "rate", 0, "total operations per second, 0 for as fast as possible")
+	flag.Float64Var(&cfg.writeRatio, "write-ratio", 0.2, "fraction of operations that create a note; the rest list notes")
+	flag.IntVar(&cfg.pageSize, "page-size", 20, "limit for note list requests, 0 to list all notes")
+	flag.Float64Var(&cfg.maxErrorRate, "max-error-rate", 0.01, "fail if more than this fraction of operations fail")
+	flag.Parse()
+	cfg.baseURL = strings.TrimSuffix(cfg.baseURL, "/")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	log.Printf("Creating %d users with %d notes each", cfg.users, cfg.seedNotes)
+	keys := make([]string, cfg.users)
+	for i := range keys {
+		key, err := createUser(client, cfg.baseURL, fmt.Sprintf("loadgen-%d-%d", time.Now().Unix(), i))
+		if err != nil {
+			log.Fatalf("Couldn't create user: %v", err)
+		}
+		keys[i] = key
+		for j := 0; j < cfg.seedNotes; j++ {
+			if err := createNote(client, cfg.baseURL, key); err != nil {
+				log.Fatalf("Couldn't create seed note: %v", err)
+			}
+		}
+	}
+
+	log.Printf("Running for %s with %d workers", cfg.duration, cfg.concurrency)
+	results := run(client, cfg, keys)
+
+	errorRate := report(os.Stdout, results, cfg.duration)
+	if errorRate > cfg.maxErrorRate {
+		log.Printf("Error rate %.2f%% is above %.2f%%", errorRate*100, cfg.maxErrorRate*100)
+		os.Exit(1)
+	}
+}
+
+// run starts the workers and collects a result for every operation.
+func run(client *http.Client, cfg config, keys []string) []result {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.duration)
+	defer cancel()
+
+	// With a rate set, workers take a token per operation; otherwise the
+	// channel is nil and never consulted.
+	var tokens <-chan time.Time
+	if cfg.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	resultsCh := make(chan result, cfg.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				key := keys[rand.Intn(len(keys))]
+				op, do := "list_notes", func() error { return listNotes(client, cfg.baseURL, key, cfg.pageSize) }
+				if rand.Float64() < cfg.writeRatio {
+					op, do = "create_note", func() error { return createNote(client, cfg.baseURL, key) }
+				}
+				start := time.Now()
+				err := do()
+				resultsCh <- result{op: op, latency: time.Since(start), err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	var results []result
+	for r := range resultsCh {
+		results = append(results, r)
+	}
+	return results
+}
+
+// report prints a table of latency percentiles per operation and returns the
+// overall error rate.
+func report(w io.Writer, results []result, elapsed time.Duration) float64 {
+	byOp := map[string][]time.Duration{}
+	errs := map[string]int{}
+	sample := map[string]error{}
+	var totalErrs int
+	for _, r := range results {
+		if r.err != nil {
+			errs[r.op]++
+			sample[r.op] = r.err
+			totalErrs++
+			continue
+		}
+		byOp[r.op] = append(byOp[r.op], r.latency)
+	}
+
+	ops := make([]string, 0, len(byOp))
+	for op := range byOp {
+		ops = append(ops, op)
+	}
+	for op := range errs {
+		if _, ok := byOp[op]; !ok {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tok\terrors\treq/s\tp50\tp90\tp99\tmax\t")
+	for _, op := range ops {
+		latencies := byOp[op]
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n", op, len(latencies), errs[op],
+			float64(len(latencies)+errs[op])/elapsed.Seconds(),
+			percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99), percentile(latencies, 100))
+	}
+	tw.Flush()
+
+	for _, op := range ops {
+		if err := sample[op]; err != nil {
+			fmt.Fprintf(w, "%s: %d errors, e.g. %v\n", op, errs[op], err)
+		}
+	}
+
+	if len(results) == 0 {
+		return 0
+	}
+	return float64(totalErrs) / float64(len(results))
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p)/100 - 1
+	return sorted[max(i, 0)].Round(100 * time.Microsecond)
+}
+
+func createUser(client *http.Client, baseURL, name string) (string, error) {
+	var user struct {
+		ApiKey string `json:"api_key"`
+	}
+	err := do(client, http.MethodPost, baseURL+"/v1/users", "", map[string]string{"name": name}, &user)
+	return user.ApiKey, err
+}
+
+func createNote(client *http.Client, baseURL, apiKey string) error {
+	body := map[string]string{"note": fmt.Sprintf("loadgen note %d", rand.Int())}
+	return do(client, http.MethodPost, baseURL+"/v1/notes", apiKey, body, nil)
+}
+
+func listNotes(client *http.Client, baseURL, apiKey string, pageSize int) error {
+	url := baseURL + "/v1/notes"
+	if pageSize > 0 {
+		url += fmt.Sprintf("?limit=%d", pageSize)
+	}
+	return do(client, http.MethodGet, url, apiKey, nil, nil)
+}
+
+// do sends a JSON request and decodes the response into out, if given. Any
+// status other than 2xx is an error.
+func do(client *http.Client, method, url, apiKey string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,172 @@
+// Command smoketest checks that a deployed Notely instance works end to end:
+// it creates a user, creates, lists, updates and deletes a note, and checks
+// that bad credentials are refused.
+//
+//	go run ./cmd/smoketest -url https://notely.example.com
+//
+// It prints a line per check and exits with status 1 if any failed, so it
+// can gate a deploy. Each run creates a new user.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/pkg/client"
+)
+
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080", "base URL of the instance")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout for each check")
+	flag.Parse()
+
+	if !run(os.Stdout, *baseURL, *timeout) {
+		os.Exit(1)
+	}
+}
+
+// run performs the checks in order and reports whether all passed. Once a
+// check fails the rest are skipped, since each depends on the one before.
+func run(w io.Writer, baseURL string, timeout time.Duration) bool {
+	anon := client.New(baseURL, "")
+	anon.MaxRetries = 1
+	var c *client.Client
+	var note client.Note
+
+	checks := []check{
+		{"create user", func(ctx context.Context) error {
+			user, err := anon.CreateUser(ctx, fmt.Sprintf("smoketest-%d", time.Now().Unix()))
+			if err != nil {
+				return err
+			}
+			if user.ApiKey == "" {
+				return errors.New("no API key in response")
+			}
+			c = anon.WithAPIKey(user.ApiKey)
+			return nil
+		}},
+		{"reject missing API key", func(ctx context.Context) error {
+			_, err := anon.GetUser(ctx)
+			return expectStatus(err, http.StatusUnauthorized)
+		}},
+		{"reject wrong API key", func(ctx context.Context) error {
+			_, err := anon.WithAPIKey("smoketest-invalid-key").GetUser(ctx)
+			return expectStatus(err, http.StatusUnauthorized, http.StatusNotFound)
+		}},
+		{"get user", func(ctx context.Context) error {
+			_, err := c.GetUser(ctx)
+			return err
+		}},
+		{"create note", func(ctx context.Context) error {
+			var err error
+			note, err = c.CreateNote(ctx, "smoketest note")
+			if err != nil {
+				return err
+			}
+			if note.Note != "smoketest note" {
+				return fmt.Errorf("note text is %q", note.Note)
+			}
+			return nil
+		}},
+		{"list notes", func(ctx context.Context) error {
+			notes, _, err := c.ListNotes(ctx, client.ListNotesOptions{})
+			if err != nil {
+				return err
+			}
+			if len(notes) != 1 || notes[0].ID != note.ID {
+				return fmt.Errorf("expected only note %s, got %d notes", note.ID, len(notes))
+			}
+			return nil
+		}},
+		{"update note", func(ctx context.Context) error {
+			updated, err := c.UpdateNote(ctx, note.ID, note.Version, "smoketest note, edited")
+			if err != nil {
+				return err
+			}
+			if updated.Note != "smoketest note, edited" || updated.Version <= note.Version {
+				return fmt.Errorf("update not applied: text %q, version %d", updated.Note, updated.Version)
+			}
+			return nil
+		}},
+		{"reject stale update", func(ctx context.Context) error {
+			_, err := c.UpdateNote(ctx, note.ID, note.Version, "smoketest stale edit")
+			return expectStatus(err, http.StatusPreconditionFailed)
+		}},
+		{"delete note", func(ctx context.Context) error {
+			deleted, err := c.DeleteNotesBefore(ctx, time.Now().Add(time.Minute))
+			if err != nil {
+				return err
+			}
+			if deleted != 1 {
+				return fmt.Errorf("deleted %d notes, expected 1", deleted)
+			}
+			notes, _, err := c.ListNotes(ctx, client.ListNotesOptions{})
+			if err != nil {
+				return err
+			}
+			if len(notes) != 0 {
+				return fmt.Errorf("%d notes left after delete", len(notes))
+			}
+			return nil
+		}},
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "check\tresult\ttime\t\n")
+	passed := true
+	for _, chk := range checks {
+		if !passed {
+			fmt.Fprintf(tw, "%s\tskipped\t\t\n", chk.name)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		err := chk.run(ctx)
+		cancel()
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			passed = false
+			fmt.Fprintf(tw, "%s\tFAILED: %s\t%s\t\n", chk.name, err, elapsed)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\tok\t%s\t\n", chk.name, elapsed)
+	}
+	tw.Flush()
+
+	if passed {
+		fmt.Fprintf(w, "All checks passed against %s\n", baseURL)
+	} else {
+		fmt.Fprintf(w, "Smoke test FAILED against %s\n", baseURL)
+	}
+	return passed
+}
+
+// expectStatus checks that err is an API error with one of codes.
+func expectStatus(err error, codes ...int) error {
+	if err == nil {
+		return errors.New("request succeeded, expected it to be refused")
+	}
+	for _, code := range codes {
+		if client.IsStatus(err, code) {
+			return nil
+		}
+	}
+	want := make([]string, len(codes))
+	for i, code := range codes {
+		want[i] = fmt.Sprint(code)
+	}
+	return fmt.Errorf("expected status %s, got: %w", strings.Join(want, " or "), err)
+}
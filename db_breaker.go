@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/statsd"
+)
+
+var errDBUnavailable = errors.New("database unavailable")
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// dbBreaker is a circuit breaker around the database connection. After
+// maxFailures consecutive failures it opens and fails every call immediately
+// for cooldown; then a single trial call decides whether it closes again.
+type dbBreaker struct {
+	maxFailures int
+	cooldown    time.Duration
+	metrics     *statsd.Client
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newDBBreaker(maxFailures int, cooldown time.Duration, metrics *statsd.Client) *dbBreaker {
+	return &dbBreaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		metrics:     metrics,
+		state:       breakerClosed,
+	}
+}
+
+// allow reports whether a call may go through.
+func (b *dbBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *dbBreaker) record(err error) {
+	// Missing rows and callers giving up say nothing about database health.
+	failed := err != nil && !errors.Is(err, sql.ErrNoRows) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		if b.state != breakerClosed {
+			b.setState(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.maxFailures {
+		b.openedAt = time.Now()
+		if b.state != breakerOpen {
+			b.setState(breakerOpen)
+		}
+	}
+}
+
+func (b *dbBreaker) setState(state string) {
+	log.Printf("Database circuit breaker %s -> %s", b.state, state)
+	b.metrics.Count("db.breaker.transitions", 1, "from:"+b.state, "to:"+state)
+	b.state = state
+}
+
+// wrap guards db with the breaker. The database and the transactions begun
+// on it are wrapped separately but share one breaker state.
+func (b *dbBreaker) wrap(db database.DBTX) breakerDB {
+	return breakerDB{db: db, breaker: b}
+}
+
+type breakerDB struct {
+	db      database.DBTX
+	breaker *dbBreaker
+}
+
+func (d breakerDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !d.breaker.allow() {
+		return nil, errDBUnavailable
+	}
+	result, err := d.db.ExecContext(ctx, query, args...)
+	d.breaker.record(err)
+	return result, err
+}
+
+func (d breakerDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if !d.breaker.allow() {
+		return nil, errDBUnavailable
+	}
+	stmt, err := d.db.PrepareContext(ctx, query)
+	d.breaker.record(err)
+	return stmt, err
+}
+
+func (d breakerDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !d.breaker.allow() {
+		return nil, errDBUnavailable
+	}
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	d.breaker.record(err)
+	return rows, err
+}
+
+func (d breakerDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !d.breaker.allow() {
+		return unavailableDB.QueryRowContext(ctx, query, args...)
+	}
+	row := d.db.QueryRowContext(ctx, query, args...)
+	d.breaker.record(row.Err())
+	return row
+}
+
+func (d breakerDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if !d.breaker.allow() {
+		return nil, errDBUnavailable
+	}
+	tx, err := beginTx(ctx, d.db, opts)
+	d.breaker.record(err)
+	return tx, err
+}
+
+// beginTx starts a transaction on db, which must be a *sql.DB or one of the
+// decorators wrapping it.
+func beginTx(ctx context.Context, db database.DBTX, opts *sql.TxOptions) (*sql.Tx, error) {
+	beginner, ok := db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("database doesn't support transactions")
+	}
+	return beginner.BeginTx(ctx, opts)
+}
+
+// unavailableDB fails every query with errDBUnavailable. A *sql.Row can't be
+// built with an error of our own, so rows for an open breaker come from a
+// database that can never be connected to, and their Scan returns the error.
+var unavailableDB = sql.OpenDB(unavailableConnector{})
+
+type unavailableConnector struct{}
+
+func (unavailableConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errDBUnavailable
+}
+
+func (unavailableConnector) Driver() driver.Driver { return unavailableDriver{} }
+
+type unavailableDriver struct{}
+
+func (unavailableDriver) Open(string) (driver.Conn, error) { return nil, errDBUnavailable }
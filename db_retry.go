@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/statsd"
+)
+
+const (
+	dbRetryBaseDelay = 50 * time.Millisecond
+	dbRetryMaxDelay  = time.Second
+)
+
+// dbRetry retries database calls that failed with a transient error, with
+// jittered exponential backoff. Writes are only retried when the database
+// reported it was busy, since then the statement definitely wasn't applied;
+// after a dropped connection a write may already have gone through.
+type dbRetry struct {
+	db       database.DBTX
+	attempts int
+	metrics  *statsd.Client
+}
+
+func newDBRetry(db database.DBTX, attempts int, metrics *statsd.Client) *dbRetry {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &dbRetry{db: db, attempts: attempts, metrics: metrics}
+}
+
+func isBusyError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "database is locked")
+}
+
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	return isBusyError(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		strings.Contains(err.Error(), "connection reset")
+}
+
+// do calls fn until it succeeds, fails permanently or runs out of attempts.
+func (d *dbRetry) do(ctx context.Context, op string, retryable func(error) bool, fn func() error) {
+	delay := dbRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= d.attempts || !retryable(err) {
+			if attempt > 1 {
+				d.metrics.Count("db.retries", int64(attempt-1), "op:"+op, "success:"+boolTag(err == nil))
+			}
+			return
+		}
+
+		// Full jitter: sleep anywhere up to the current backoff.
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(delay))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, dbRetryMaxDelay)
+	}
+}
+
+func boolTag(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+func (d *dbRetry) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	d.do(ctx, "exec", func(err error) bool { return isBusyError(err) }, func() error {
+		result, err = d.db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (d *dbRetry) PrepareContext(ctx context.Context, query string) (stmt *sql.Stmt, err error) {
+	d.do(ctx, "prepare", isRetryableError, func() error {
+		stmt, err = d.db.PrepareContext(ctx, query)
+		return err
+	})
+	return stmt, err
+}
+
+// queryRetryable picks the retry rule for a query. Statements using RETURNING
+// come through the query methods but write, so only SELECTs get the looser
+// rule.
+func queryRetryable(query string) func(error) bool {
+	if strings.HasPrefix(strings.ToUpper(stripSQLComments(query)), "SELECT") {
+		return isRetryableError
+	}
+	return isBusyError
+}
+
+func (d *dbRetry) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	d.do(ctx, "query", queryRetryable(query), func() error {
+		rows, err = d.db.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (d *dbRetry) QueryRowContext(ctx context.Context, query string, args ...interface{}) (row *sql.Row) {
+	d.do(ctx, "query_row", queryRetryable(query), func() error {
+		row = d.db.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// stripSQLComments drops leading "--" comment lines, such as sqlc's
+// "-- name:" header.
+func stripSQLComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		if !strings.HasPrefix(query, "--") {
+			return query
+		}
+		_, rest, found := strings.Cut(query, "\n")
+		if !found {
+			return ""
+		}
+		query = rest
+	}
+}
+
+// BeginTx retries on any transient error, since nothing has been written yet.
+func (d *dbRetry) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx *sql.Tx, err error) {
+	d.do(ctx, "begin", isRetryableError, func() error {
+		tx, err = beginTx(ctx, d.db, opts)
+		return err
+	})
+	return tx, err
+}
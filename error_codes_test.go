@@ -0,0 +1,139 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+// TestErrorCodesInCatalog checks every error code the handlers use against
+// the English catalog, so a typo or a missing entry fails here rather than
+// sending clients the code as the message. Codes with details must have
+// prefix messages, and codes without must not.
+func TestErrorCodesInCatalog(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check := func(node ast.Node, code string, prefix bool) {
+			pos := fset.Position(node.Pos())
+			switch {
+			case !i18n.Has(code):
+				t.Errorf("%s: code %q isn't in the catalog", pos, code)
+			case i18n.IsPrefix(code) != prefix:
+				t.Errorf("%s: code %q is used with prefix=%v, but its message says otherwise", pos, code, prefix)
+			}
+		}
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				fn, ok := node.Fun.(*ast.Ident)
+				if !ok || len(node.Args) < 3 {
+					return true
+				}
+				// Codes that aren't literals are passed along from an error
+				// or a table, and are checked where they're written below.
+				code, ok := stringLit(node.Args[2])
+				if !ok {
+					return true
+				}
+				switch fn.Name {
+				case "respondWithError":
+					check(node, code, false)
+				case "respondWithErrorDetail":
+					check(node, code, true)
+				}
+			case *ast.CompositeLit:
+				code, hasDetail := "", false
+				for _, elt := range node.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					switch key, _ := kv.Key.(*ast.Ident); {
+					case key == nil:
+					case key.Name == "code":
+						code, _ = stringLit(kv.Value)
+					case key.Name == "detail":
+						hasDetail = true
+					}
+				}
+				if code != "" {
+					check(node, code, hasDetail)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func TestRespondWithAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "coded",
+			err:        errInvalidCursor,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_cursor",
+			wantMsg:    "invalid cursor",
+		},
+		{
+			name:       "with detail",
+			err:        &noteRejectedError{reason: "spam"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "note_rejected_reason",
+			wantMsg:    "Note rejected by moderation: spam",
+		},
+		{
+			name:       "not meant for clients",
+			err:        strconv.ErrSyntax,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantMsg:    i18n.Message("internal_error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respondWithAPIError(w, http.StatusBadRequest, tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, `"code":"`+tt.wantCode+`"`) || !strings.Contains(body, `"error":"`+tt.wantMsg+`"`) {
+				t.Errorf("body = %s, want code %q and message %q", body, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
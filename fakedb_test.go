@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+// fakeQueryFunc answers a query sent to a fakeDB with the columns and rows
+// of its result.
+type fakeQueryFunc func(query string, args []driver.Value) (columns []string, rows [][]driver.Value, err error)
+
+// newFakeDB returns a database that answers every query and statement with
+// query, so handlers can be tested without a real database. Statements get
+// the same call and their rows are ignored.
+func newFakeDB(t testing.TB, query fakeQueryFunc) *sql.DB {
+	db := sql.OpenDB(fakeConnector{query: query})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeConnector struct {
+	query fakeQueryFunc
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn(c), nil
+}
+
+func (c fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDB is opened with sql.OpenDB")
+}
+
+type fakeConn struct {
+	query fakeQueryFunc
+}
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB doesn't prepare statements")
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.query(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, _, err := c.query(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// noteColumns are the columns of the notes table, in the order sqlc scans
+// them.
+var noteColumns = []string{
+	"id", "created_at", "updated_at", "note", "user_id", "due_at", "reminded_at", "slug",
+	"version", "language", "title", "title_custom", "properties", "latitude", "longitude",
+	"property_project",
+}
+
+// noteRow returns note as a row of noteColumns.
+func noteRow(note database.Note) []driver.Value {
+	nullString := func(s sql.NullString) driver.Value {
+		if !s.Valid {
+			return nil
+		}
+		return s.String
+	}
+	nullFloat := func(f sql.NullFloat64) driver.Value {
+		if !f.Valid {
+			return nil
+		}
+		return f.Float64
+	}
+	return []driver.Value{
+		note.ID, note.CreatedAt, note.UpdatedAt, note.Note, note.UserID,
+		nullString(note.DueAt), nullString(note.RemindedAt), nullString(note.Slug),
+		note.Version, nullString(note.Language), nullString(note.Title), note.TitleCustom,
+		note.Properties, nullFloat(note.Latitude), nullFloat(note.Longitude),
+		nullString(note.PropertyProject),
+	}
+}
+
+// newTestAPIConfig returns an apiConfig whose database answers with query.
+func newTestAPIConfig(t testing.TB, query fakeQueryFunc) *apiConfig {
+	cfg := &apiConfig{DB: database.New(newFakeDB(t, query))}
+	cfg.runtime.Store(&runtimeConfig{})
+	return cfg
+}
+
+var testNote = database.Note{
+	ID:         "0190d6d4-7ac4-7b3e-9d67-3a2a8d8c4f01",
+	CreatedAt:  "2024-07-01T12:00:00Z",
+	UpdatedAt:  "2024-07-01T12:00:00Z",
+	Note:       "Buy milk",
+	UserID:     "0190d6d4-7ac4-7b3e-9d67-3a2a8d8c4f00",
+	Version:    1,
+	Properties: "{}",
+}
+
+var testUser = database.User{
+	ID:        testNote.UserID,
+	CreatedAt: "2024-07-01T12:00:00Z",
+	UpdatedAt: "2024-07-01T12:00:00Z",
+	Name:      "test",
+	ApiKey:    "test-api-key",
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// respondWithFields is respondWithJSON for list and get endpoints. With
+// ?fields=id,note only those fields of each object are sent, which keeps
+// payloads small for clients that only render previews.
+func respondWithFields(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	if !r.URL.Query().Has("fields") {
+		respondWithJSON(w, code, payload)
+		return
+	}
+	projected, err := selectFields(payload, r.URL.Query().Get("fields"))
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondWithJSON(w, code, projected)
+}
+
+// selectFields encodes payload, a struct or a slice of structs, keeping only
+// the named JSON fields of each object. Fields keep the order of the struct.
+// Naming a field the struct doesn't have is an error, so typos don't
+// silently return empty objects.
+func selectFields(payload any, fields string) (json.RawMessage, error) {
+	t := reflect.TypeOf(payload)
+	isList := t.Kind() == reflect.Slice
+	if isList {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, &apiError{code: "fields_not_supported"}
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(fields, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, &apiError{code: "fields_empty"}
+	}
+	var keep []string
+	for _, name := range jsonFieldNames(t) {
+		if wanted[name] {
+			keep = append(keep, name)
+			delete(wanted, name)
+		}
+	}
+	for name := range wanted {
+		return nil, &apiError{code: "unknown_field", detail: name}
+	}
+
+	full, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !isList {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(full, &obj); err != nil {
+			return nil, err
+		}
+		return writeFields(nil, obj, keep), nil
+	}
+
+	var objs []map[string]json.RawMessage
+	if err := json.Unmarshal(full, &objs); err != nil {
+		return nil, err
+	}
+	if objs == nil {
+		return json.RawMessage("null"), nil
+	}
+	out := []byte{'['}
+	for i, obj := range objs {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = writeFields(out, obj, keep)
+	}
+	return append(out, ']'), nil
+}
+
+// writeFields appends obj to buf as a JSON object holding only keep, in
+// that order. Fields left out by omitempty stay left out.
+func writeFields(buf []byte, obj map[string]json.RawMessage, keep []string) []byte {
+	buf = append(buf, '{')
+	first := true
+	for _, name := range keep {
+		value, ok := obj[name]
+		if !ok {
+			continue
+		}
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		key, _ := json.Marshal(name)
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, bytes.TrimSpace(value)...)
+	}
+	return append(buf, '}')
+}
+
+// jsonFieldNames lists the names t's exported fields have in JSON.
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
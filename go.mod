@@ -8,6 +8,8 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/joho/godotenv v1.5.1
 	github.com/tursodatabase/libsql-client-go v0.0.0-20240220085343-4ae0eb9d0898
+	golang.org/x/net v0.21.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	erasureConfirmTTL = time.Hour
+	erasureBatchSize  = 100
+)
+
+func (cfg *apiConfig) handlerAccountExportCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithNewExport(w, r, user, exportKindAccount)
+}
+
+// buildAccountExport collects everything stored about a user into a single
+// JSON document. API keys and Slack webhook URLs are credentials rather than
+// personal data and are left out.
+func (cfg *apiConfig) buildAccountExport(ctx context.Context, userID string) (string, error) {
+	type profile struct {
+		ID        string    `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+		Name      string    `json:"name"`
+	}
+	type reaction struct {
+		NoteID    string `json:"note_id"`
+		Emoji     string `json:"emoji"`
+		CreatedAt string `json:"created_at"`
+	}
+	type archive struct {
+		ExportedAt        time.Time  `json:"exported_at"`
+		Profile           profile    `json:"profile"`
+		Notes             []Note     `json:"notes"`
+		Comments          []Comment  `json:"comments"`
+		Reactions         []reaction `json:"reactions"`
+		Templates         []Template `json:"templates"`
+		Schedules         []Schedule `json:"schedules"`
+		SlackIntegrations []string   `json:"slack_integrations"`
+	}
+
+	dbUser, err := cfg.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	user, err := databaseUserToUser(dbUser)
+	if err != nil {
+		return "", err
+	}
+	result := archive{
+		ExportedAt: time.Now().UTC(),
+		Profile: profile{
+			ID:        user.ID,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+			Name:      user.Name,
+		},
+		Notes:             []Note{},
+		Reactions:         []reaction{},
+		SlackIntegrations: []string{},
+	}
+
+	err = cfg.forEachNoteBatch(ctx, userID, func(batch []database.Note) error {
+		converted, err := databasePostsToPosts(batch)
+		if err != nil {
+			return err
+		}
+		result.Notes = append(result.Notes, converted...)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := cfg.DB.GetCommentsByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if result.Comments, err = databaseCommentsToComments(comments); err != nil {
+		return "", err
+	}
+
+	reactions, err := cfg.DB.GetReactionsByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range reactions {
+		result.Reactions = append(result.Reactions, reaction{NoteID: r.NoteID, Emoji: r.Emoji, CreatedAt: r.CreatedAt})
+	}
+
+	templates, err := cfg.DB.GetTemplatesForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if result.Templates, err = databaseTemplatesToTemplates(templates); err != nil {
+		return "", err
+	}
+
+	schedules, err := cfg.DB.GetSchedulesForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if result.Schedules, err = databaseSchedulesToSchedules(schedules); err != nil {
+		return "", err
+	}
+
+	integrations, err := cfg.DB.GetSlackIntegrationsForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, integration := range integrations {
+		result.SlackIntegrations = append(result.SlackIntegrations, integration.ID)
+	}
+
+	dat, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(dat), nil
+}
+
+type erasureStatus struct {
+	Status      string     `json:"status"`
+	RequestedAt time.Time  `json:"requested_at"`
+	EraseAfter  *time.Time `json:"erase_after,omitempty"`
+}
+
+func databaseErasureRequestToStatus(req database.ErasureRequest) (erasureStatus, error) {
+	requestedAt, err := parseDBTime(req.CreatedAt)
+	if err != nil {
+		return erasureStatus{}, err
+	}
+	status := erasureStatus{
+		Status:      "awaiting_confirmation",
+		RequestedAt: requestedAt,
+	}
+	if req.EraseAfter.Valid {
+		eraseAfter, err := parseDBTime(req.EraseAfter.String)
+		if err != nil {
+			return erasureStatus{}, err
+		}
+		status.Status = "scheduled"
+		status.EraseAfter = &eraseAfter
+	}
+	return status, nil
+}
+
+// handlerErasureRequest is the first step of account erasure. It returns a
+// confirmation token that has to be sent back within an hour.
+func (cfg *apiConfig) handlerErasureRequest(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		ConfirmationToken string    `json:"confirmation_token"`
+		ExpiresAt         time.Time `json:"expires_at"`
+	}
+
+	if !cfg.checkErasureAllowed(w, r, user) {
+		return
+	}
+
+	token, err := generateRandomSHA256Hash()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "generate_confirmation_token_failed", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	err = cfg.DB.UpsertErasureRequest(r.Context(), database.UpsertErasureRequestParams{
+		UserID:            user.ID,
+		CreatedAt:         now.Format(time.RFC3339),
+		ConfirmationToken: token,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_erasure_request_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, response{
+		ConfirmationToken: token,
+		ExpiresAt:         now.Add(erasureConfirmTTL),
+	})
+}
+
+// handlerErasureConfirm schedules the account for erasure once the grace
+// period has passed. Until then the request can be cancelled.
+func (cfg *apiConfig) handlerErasureConfirm(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		ConfirmationToken string `json:"confirmation_token"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+
+	if !cfg.checkErasureAllowed(w, r, user) {
+		return
+	}
+
+	now := time.Now().UTC()
+	n, err := cfg.DB.ConfirmErasureRequest(r.Context(), database.ConfirmErasureRequestParams{
+		ConfirmedAt:       sql.NullString{String: now.Format(time.RFC3339), Valid: true},
+		EraseAfter:        sql.NullString{String: now.Add(cfg.erasureGracePeriod).Format(time.RFC3339), Valid: true},
+		UserID:            user.ID,
+		ConfirmationToken: params.ConfirmationToken,
+		CreatedAt:         now.Add(-erasureConfirmTTL).Format(time.RFC3339),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "confirm_erasure_request_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusBadRequest, "invalid_confirmation_token", nil)
+		return
+	}
+
+	cfg.respondWithErasureStatus(w, r, user)
+}
+
+// checkErasureAllowed responds with 409 if the user or any of their notes is
+// under legal hold. The erasure job checks again before deleting.
+func (cfg *apiConfig) checkErasureAllowed(w http.ResponseWriter, r *http.Request, user database.User) bool {
+	onHold, err := cfg.DB.IsUserOnLegalHold(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "check_legal_hold_failed", err)
+		return false
+	}
+	if onHold != 0 {
+		respondWithError(w, http.StatusConflict, "account_on_legal_hold", nil)
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) handlerErasureGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithErasureStatus(w, r, user)
+}
+
+func (cfg *apiConfig) respondWithErasureStatus(w http.ResponseWriter, r *http.Request, user database.User) {
+	req, err := cfg.DB.GetErasureRequest(r.Context(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "no_erasure_requested", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_erasure_request_failed", err)
+		return
+	}
+
+	status, err := databaseErasureRequestToStatus(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_erasure_request_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+func (cfg *apiConfig) handlerErasureCancel(w http.ResponseWriter, r *http.Request, user database.User) {
+	n, err := cfg.DB.DeleteErasureRequest(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "cancel_erasure_request_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "no_erasure_requested", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runErasures deletes accounts whose grace period has passed, skipping any
+// under legal hold. Everything a user owns cascades from the users row; the
+// sync change log has no foreign key, so it is cleared separately.
+func (cfg *apiConfig) runErasures(ctx context.Context) error {
+	userIDs, err := cfg.DB.GetDueErasures(ctx, database.GetDueErasuresParams{
+		EraseAfter: sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true},
+		Limit:      erasureBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := cfg.withTx(ctx, func(q *database.Queries) error {
+			if err := q.DeleteUser(ctx, userID); err != nil {
+				return err
+			}
+			return q.DeleteNoteChangesForUser(ctx, userID)
+		})
+		if err != nil {
+			return err
+		}
+		cfg.staleNoteLists.forget(userID)
+		cfg.staleSuspensions.forget(userID)
+		log.Printf("Erased account %s", userID)
+	}
+	return nil
+}
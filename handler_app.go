@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+// appNotesLimit caps how many notes the HTML UI lists.
+const appNotesLimit = 200
+
+//go:embed templates/app/*.html
+var appTemplateFiles embed.FS
+
+// appTemplates holds one template per page, each combined with the shared
+// layout.
+var appTemplates = func() map[string]*template.Template {
+	pages := map[string]*template.Template{}
+	for _, page := range []string{"login.html", "notes.html", "edit.html", "error.html"} {
+		pages[page] = template.Must(template.ParseFS(appTemplateFiles, "templates/app/layout.html", "templates/app/"+page))
+	}
+	return pages
+}()
+
+// renderApp executes a page into a buffer first, so a template error doesn't
+// leave a half-written page behind.
+func renderApp(w http.ResponseWriter, code int, page string, data any) {
+	var buf bytes.Buffer
+	if err := appTemplates[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		log.Printf("Error rendering %s: %s", page, err)
+		http.Error(w, "Couldn't render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
+
+func renderAppError(w http.ResponseWriter, code int, msg string) {
+	renderApp(w, code, "error.html", struct{ Message string }{msg})
+}
+
+// renderAppNoteError maps the errors returned by createNote and updateNote
+// to an error page.
+func renderAppNoteError(w http.ResponseWriter, err error) {
+	var invalid *noteTextError
+	if errors.As(err, &invalid) {
+		renderAppError(w, http.StatusBadRequest, invalid.Error())
+		return
+	}
+	var rejected *noteRejectedError
+	if errors.As(err, &rejected) {
+		renderAppError(w, http.StatusUnprocessableEntity, rejected.Error())
+		return
+	}
+	var throttled *noteThrottledError
+	if errors.As(err, &throttled) {
+		renderAppError(w, http.StatusTooManyRequests, throttled.Error())
+		return
+	}
+	var overLimit *planLimitError
+	if errors.As(err, &overLimit) {
+		renderAppError(w, http.StatusForbidden, overLimit.Error())
+		return
+	}
+	if errors.Is(err, errNoteVersionConflict) {
+		renderAppError(w, http.StatusPreconditionFailed, "This note was changed elsewhere since you opened it. Reload it and try again.")
+		return
+	}
+	log.Printf("Couldn't save note: %s", err)
+	renderAppError(w, http.StatusInternalServerError, "Couldn't save note")
+}
+
+func (cfg *apiConfig) handlerAppLoginForm(w http.ResponseWriter, r *http.Request) {
+	renderApp(w, http.StatusOK, "login.html", struct{ Error string }{})
+}
+
+func (cfg *apiConfig) handlerAppLogin(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		renderAppError(w, http.StatusForbidden, "Cross-site request refused")
+		return
+	}
+
+	user, err := cfg.DB.GetUser(r.Context(), r.PostFormValue("api_key"))
+	if errors.Is(err, sql.ErrNoRows) {
+		renderApp(w, http.StatusUnauthorized, "login.html", struct{ Error string }{"Unknown API key"})
+		return
+	}
+	if err != nil {
+		log.Printf("Couldn't get user: %s", err)
+		renderAppError(w, http.StatusInternalServerError, "Couldn't log in")
+		return
+	}
+
+	if err := cfg.startSession(w, r, user); err != nil {
+		log.Printf("Couldn't create session: %s", err)
+		renderAppError(w, http.StatusInternalServerError, "Couldn't log in")
+		return
+	}
+	http.Redirect(w, r, "/app", http.StatusSeeOther)
+}
+
+func (cfg *apiConfig) handlerAppLogout(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		renderAppError(w, http.StatusForbidden, "Cross-site request refused")
+		return
+	}
+
+	if err := cfg.endSession(w, r); err != nil {
+		log.Printf("Couldn't delete session: %s", err)
+	}
+	http.Redirect(w, r, "/app/login", http.StatusSeeOther)
+}
+
+func (cfg *apiConfig) handlerAppNotes(w http.ResponseWriter, r *http.Request, user database.User) {
+	notes, err := cfg.DB.GetLatestNotesForUser(r.Context(), database.GetLatestNotesForUserParams{
+		UserID: user.ID,
+		Limit:  appNotesLimit,
+	})
+	if err != nil {
+		log.Printf("Couldn't get notes: %s", err)
+		renderAppError(w, http.StatusInternalServerError, "Couldn't get notes")
+		return
+	}
+
+	renderApp(w, http.StatusOK, "notes.html", struct {
+		User  database.User
+		Notes []database.Note
+	}{user, notes})
+}
+
+func (cfg *apiConfig) handlerAppNotesCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	if _, _, err := cfg.createNote(r.Context(), user.ID, r.PostFormValue("title"), r.PostFormValue("note")); err != nil {
+		renderAppNoteError(w, err)
+		return
+	}
+	http.Redirect(w, r, "/app", http.StatusSeeOther)
+}
+
+// getAppNote is getNoteForUser for the HTML UI.
+func (cfg *apiConfig) getAppNote(w http.ResponseWriter, r *http.Request, user database.User) (database.Note, bool) {
+	note, err := cfg.DB.GetNote(r.Context(), chi.URLParam(r, "noteID"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && note.UserID != user.ID) {
+		renderAppError(w, http.StatusNotFound, "Note not found")
+		return database.Note{}, false
+	}
+	if err != nil {
+		log.Printf("Couldn't get note: %s", err)
+		renderAppError(w, http.StatusInternalServerError, "Couldn't get note")
+		return database.Note{}, false
+	}
+	return note, true
+}
+
+func (cfg *apiConfig) handlerAppNoteEdit(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getAppNote(w, r, user)
+	if !ok {
+		return
+	}
+	renderApp(w, http.StatusOK, "edit.html", struct{ Note database.Note }{note})
+}
+
+func (cfg *apiConfig) handlerAppNoteUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getAppNote(w, r, user)
+	if !ok {
+		return
+	}
+	// The form carries the version that was edited, so saving over someone
+	// else's change shows an error instead of losing it.
+	version, err := strconv.ParseInt(r.PostFormValue("version"), 10, 64)
+	if err != nil {
+		renderAppError(w, http.StatusBadRequest, "Missing note version")
+		return
+	}
+	note.Version = version
+	// The form always has the title field, so leaving it blank goes back
+	// to a derived title.
+	title := r.PostFormValue("title")
+	if _, _, err := cfg.updateNote(r.Context(), note, &title, r.PostFormValue("note")); err != nil {
+		renderAppNoteError(w, err)
+		return
+	}
+	http.Redirect(w, r, "/app", http.StatusSeeOther)
+}
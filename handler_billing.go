@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/stripe"
+)
+
+const (
+	auditActorStripe = "stripe"
+
+	maxWebhookBodySize = 1 << 20
+)
+
+// stripeConfig is set from STRIPE_* variables. The webhook endpoint is only
+// served when webhookSecret is set, and checkout and the customer portal only
+// when client and returnURL are.
+type stripeConfig struct {
+	webhookSecret string
+	proPriceID    string
+	client        *stripe.Client
+	returnURL     string
+}
+
+func (c stripeConfig) checkoutEnabled() bool {
+	return c.client != nil && c.returnURL != "" && c.proPriceID != ""
+}
+
+// handlerStripeWebhook applies subscription changes from Stripe to users'
+// plans. A completed, paid Checkout or an active subscription to
+// STRIPE_PRO_PRICE_ID moves its user to pro; any other subscription state,
+// including cancellation, moves them to free. The user is found from the
+// user_id metadata set by handlerBillingCheckout or, failing that, the Stripe
+// customer recorded earlier.
+func (cfg *apiConfig) handlerStripeWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "read_body_failed", err)
+		return
+	}
+	event, err := stripe.ParseWebhook(payload, r.Header.Get("Stripe-Signature"), cfg.stripe.webhookSecret, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid_webhook", err)
+		return
+	}
+
+	var userID, plan, customer string
+	var details map[string]string
+	switch event.Type {
+	case "checkout.session.completed":
+		var session stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			respondWithError(w, http.StatusBadRequest, "decode_checkout_session_failed", err)
+			return
+		}
+		if session.Mode != "subscription" || !session.Paid() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		userID, err = cfg.stripeCheckoutUserID(r.Context(), session)
+		plan, customer = planPro, session.Customer
+		details = map[string]string{
+			"plan":         plan,
+			"event":        event.ID,
+			"checkout":     session.ID,
+			"subscription": session.Subscription,
+		}
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		var sub stripe.Subscription
+		if err := json.Unmarshal(event.Data.Object, &sub); err != nil {
+			respondWithError(w, http.StatusBadRequest, "decode_subscription_failed", err)
+			return
+		}
+		plan = planFree
+		if event.Type != "customer.subscription.deleted" && sub.Active() && sub.HasPrice(cfg.stripe.proPriceID) {
+			plan = planPro
+		}
+		userID, err = cfg.stripeUserID(r.Context(), sub)
+		customer = sub.Customer
+		details = map[string]string{
+			"plan":         plan,
+			"event":        event.ID,
+			"subscription": sub.ID,
+			"status":       sub.Status,
+		}
+	default:
+		// Acknowledge events we don't use so Stripe doesn't retry them.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Stripe event %s is for an unknown user or customer, ignoring", event.ID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "find_user_failed", err)
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.SetUserPlan(r.Context(), database.SetUserPlanParams{
+			UserID:           userID,
+			Plan:             plan,
+			StripeCustomerID: sql.NullString{String: customer, Valid: customer != ""},
+			UpdatedAt:        time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(r.Context(), q, auditActorStripe, "plan.set", "user", userID, details)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_plan_failed", err)
+		return
+	}
+	cfg.plans.forget(userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) stripeCheckoutUserID(ctx context.Context, session stripe.CheckoutSession) (string, error) {
+	userID := session.ClientReferenceID
+	if userID == "" {
+		userID = session.Metadata["user_id"]
+	}
+	if userID == "" {
+		return "", sql.ErrNoRows
+	}
+	user, err := cfg.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+func (cfg *apiConfig) stripeUserID(ctx context.Context, sub stripe.Subscription) (string, error) {
+	if userID := sub.Metadata["user_id"]; userID != "" {
+		user, err := cfg.DB.GetUserByID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return user.ID, nil
+	}
+	return cfg.DB.GetUserIDByStripeCustomer(ctx, sql.NullString{String: sub.Customer, Valid: true})
+}
+
+// handlerBillingCheckout starts a Stripe Checkout for the pro plan and
+// returns its URL for the client to send the user to. The upgrade itself
+// happens when Stripe reports the payment to handlerStripeWebhook.
+func (cfg *apiConfig) handlerBillingCheckout(w http.ResponseWriter, r *http.Request, user database.User) {
+	plan, err := cfg.DB.GetPlanForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_plan_failed", err)
+		return
+	}
+	if plan.Name == planPro {
+		respondWithError(w, http.StatusConflict, "already_on_pro_plan", nil)
+		return
+	}
+	customer, err := cfg.DB.GetStripeCustomerForUser(r.Context(), user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "get_billing_account_failed", err)
+		return
+	}
+
+	session, err := cfg.stripe.client.CreateCheckoutSession(r.Context(), stripe.CheckoutParams{
+		PriceID:    cfg.stripe.proPriceID,
+		SuccessURL: withQueryParam(cfg.stripe.returnURL, "checkout", "success"),
+		CancelURL:  withQueryParam(cfg.stripe.returnURL, "checkout", "cancel"),
+		UserID:     user.ID,
+		CustomerID: customer.String,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "start_checkout_failed", err)
+		return
+	}
+
+	type response struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	respondWithJSON(w, http.StatusCreated, response{ID: session.ID, URL: session.URL})
+}
+
+// handlerBillingPortal returns a link to Stripe's customer portal, where the
+// user can update payment details or cancel. Only users who have been
+// through checkout have a Stripe customer to manage.
+func (cfg *apiConfig) handlerBillingPortal(w http.ResponseWriter, r *http.Request, user database.User) {
+	customer, err := cfg.DB.GetStripeCustomerForUser(r.Context(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !customer.Valid) {
+		respondWithError(w, http.StatusNotFound, "no_billing_account", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_billing_account_failed", err)
+		return
+	}
+
+	session, err := cfg.stripe.client.CreatePortalSession(r.Context(), customer.String, cfg.stripe.returnURL)
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "open_billing_portal_failed", err)
+		return
+	}
+
+	type response struct {
+		URL string `json:"url"`
+	}
+	respondWithJSON(w, http.StatusOK, response{URL: session.URL})
+}
+
+// withQueryParam adds key=value to rawURL's query, keeping any it has.
+func withQueryParam(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/ical"
+)
+
+// calendarFeedToken signs the user ID with the user's API key, so the feed
+// URL stops working when the key is rotated or revoked.
+func calendarFeedToken(user database.User) string {
+	mac := hmac.New(sha256.New, []byte(user.ApiKey))
+	mac.Write([]byte("calendar:" + user.ID))
+	return user.ID + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (cfg *apiConfig) handlerCalendarTokenGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+
+	token := calendarFeedToken(user)
+	respondWithJSON(w, http.StatusOK, response{
+		Token: token,
+		URL:   "/v1/calendar.ics?token=" + token,
+	})
+}
+
+func (cfg *apiConfig) handlerCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	userID, _, ok := strings.Cut(token, ".")
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "invalid_feed_token", nil)
+		return
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !hmac.Equal([]byte(token), []byte(calendarFeedToken(user)))) {
+		respondWithError(w, http.StatusUnauthorized, "invalid_feed_token", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_user_failed", err)
+		return
+	}
+	if !cfg.allowUnsuspended(w, r, user.ID) {
+		return
+	}
+
+	notes, err := cfg.DB.GetNotesWithDueAtForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+		return
+	}
+
+	calendarEvents := make([]ical.Event, 0, len(notes))
+	for _, note := range notes {
+		dueAt, err := parseDBTime(note.DueAt.String)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "parse_due_date_failed", err)
+			return
+		}
+		updatedAt, err := parseDBTime(note.UpdatedAt)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "parse_updated_date_failed", err)
+			return
+		}
+		calendarEvents = append(calendarEvents, ical.Event{
+			UID:         note.ID + "@notely",
+			Stamp:       updatedAt,
+			Start:       dueAt,
+			Summary:     noteLabel(note.Note),
+			Description: note.Note,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := ical.Write(w, "Notely reminders", calendarEvents); err != nil {
+		log.Printf("Error writing calendar feed: %v", err)
+	}
+}
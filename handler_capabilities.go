@@ -0,0 +1,38 @@
+package main
+
+import "net/http"
+
+func (cfg *apiConfig) handlerCapabilities(w http.ResponseWriter, r *http.Request) {
+	type limits struct {
+		MaxPageSize int `json:"max_page_size"`
+	}
+	type response struct {
+		Features map[string]bool `json:"features"`
+		Limits   limits          `json:"limits"`
+	}
+
+	hasDB := cfg.DB != nil
+	respondWithJSON(w, http.StatusOK, response{
+		Features: map[string]bool{
+			"notes":       hasDB,
+			"templates":   hasDB,
+			"reminders":   hasDB,
+			"calendar":    hasDB,
+			"schedules":   hasDB,
+			"slack":       hasDB,
+			"telegram":    hasDB && cfg.telegram != nil,
+			"export":      hasDB,
+			"sync":        hasDB,
+			"admin":       hasDB && cfg.adminAPIKey != "",
+			"billing":     hasDB && cfg.stripe.checkoutEnabled(),
+			"search":      false,
+			"attachments": false,
+			"webhooks":    false,
+			"ai":          false,
+			"orgs":        false,
+		},
+		Limits: limits{
+			MaxPageSize: maxPageSize,
+		},
+	})
+}
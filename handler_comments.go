@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+	"github.com/go-chi/chi"
+)
+
+func (cfg *apiConfig) handlerCommentsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Body == "" {
+		respondWithError(w, http.StatusBadRequest, "comment_body_is_required", nil)
+		return
+	}
+
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	id := cfg.ids.New()
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.CreateComment(r.Context(), database.CreateCommentParams{
+			ID:        id,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+			Body:      params.Body,
+			NoteID:    note.ID,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(r.Context(), q, events.New(events.CommentCreated, note.UserID, id))
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_comment_failed", err)
+		return
+	}
+
+	comment, err := cfg.DB.GetComment(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_comment_failed", err)
+		return
+	}
+
+	commentResp, err := databaseCommentToComment(comment)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_comment_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, commentResp)
+}
+
+func (cfg *apiConfig) handlerCommentsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	comments, err := cfg.DB.GetCommentsForNote(r.Context(), note.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_comments_for_note_failed", err)
+		return
+	}
+
+	commentsResp, err := databaseCommentsToComments(comments)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_comments_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, commentsResp)
+}
+
+// getCommentForAuthor looks up the comment in the commentID URL parameter on
+// a note the caller can access. Only the comment's author may change it.
+func (cfg *apiConfig) getCommentForAuthor(w http.ResponseWriter, r *http.Request, user database.User) (database.Comment, bool) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return database.Comment{}, false
+	}
+
+	comment, err := cfg.DB.GetComment(r.Context(), chi.URLParam(r, "commentID"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && comment.NoteID != note.ID) {
+		respondWithError(w, http.StatusNotFound, "comment_not_found", nil)
+		return database.Comment{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_comment_failed", err)
+		return database.Comment{}, false
+	}
+	if comment.UserID != user.ID {
+		respondWithError(w, http.StatusForbidden, "not_comment_author", nil)
+		return database.Comment{}, false
+	}
+	return comment, true
+}
+
+func (cfg *apiConfig) handlerCommentsUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Body == "" {
+		respondWithError(w, http.StatusBadRequest, "comment_body_is_required", nil)
+		return
+	}
+
+	comment, ok := cfg.getCommentForAuthor(w, r, user)
+	if !ok {
+		return
+	}
+
+	err = cfg.DB.UpdateComment(r.Context(), database.UpdateCommentParams{
+		Body:      params.Body,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		ID:        comment.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_comment_failed", err)
+		return
+	}
+
+	comment, err = cfg.DB.GetComment(r.Context(), comment.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_comment_failed", err)
+		return
+	}
+
+	commentResp, err := databaseCommentToComment(comment)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_comment_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, commentResp)
+}
+
+func (cfg *apiConfig) handlerCommentsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	comment, ok := cfg.getCommentForAuthor(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := cfg.DB.DeleteComment(r.Context(), comment.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_comment_failed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+	maxDrainTimeout     = 5 * time.Minute
+	drainPollInterval   = 100 * time.Millisecond
+)
+
+// drainState tracks in-flight requests so an instance can be taken out of
+// rotation cleanly. shutdown is called to stop the server when a drain asks
+// to exit.
+type drainState struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+	shutdown func()
+}
+
+func (d *drainState) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (cfg *apiConfig) handlerReadyz(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Status   string `json:"status"`
+		Draining bool   `json:"draining"`
+		InFlight int64  `json:"in_flight"`
+		shedState
+	}
+
+	resp := response{
+		Status:    "ok",
+		Draining:  cfg.drain.draining.Load(),
+		InFlight:  cfg.drain.inFlight.Load(),
+		shedState: cfg.shedder.state(),
+	}
+	code := http.StatusOK
+	if resp.Draining {
+		resp.Status = "draining"
+		code = http.StatusServiceUnavailable
+	} else if resp.Shedding {
+		resp.Status = "shedding"
+	}
+	respondWithJSON(w, code, resp)
+}
+
+// handlerDrain fails readiness, then waits up to ?timeout_seconds for other
+// in-flight requests to finish. With ?exit=true the server shuts down
+// gracefully after responding.
+func (cfg *apiConfig) handlerDrain(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Drained  bool  `json:"drained"`
+		InFlight int64 `json:"in_flight"`
+		WaitedMs int64 `json:"waited_ms"`
+		Exiting  bool  `json:"exiting"`
+	}
+
+	timeout := defaultDrainTimeout
+	if s := r.URL.Query().Get("timeout_seconds"); s != "" {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxDrainTimeout {
+			respondWithErrorDetail(w, http.StatusBadRequest, "invalid_timeout_seconds", strconv.Itoa(int(maxDrainTimeout.Seconds())), err)
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	exit := r.URL.Query().Get("exit") == "true"
+
+	cfg.drain.draining.Store(true)
+
+	start := time.Now()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	// This request is in flight too.
+	others := func() int64 { return cfg.drain.inFlight.Load() - 1 }
+	for others() > 0 && time.Since(start) < timeout {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	resp := response{
+		Drained:  others() <= 0,
+		InFlight: max(others(), 0),
+		WaitedMs: time.Since(start).Milliseconds(),
+		Exiting:  exit && cfg.drain.shutdown != nil,
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+
+	if resp.Exiting {
+		// Shut down after this response has been written; Shutdown waits
+		// for it and any stragglers to finish.
+		go cfg.drain.shutdown()
+	}
+}
+
+func (cfg *apiConfig) handlerUndrain(w http.ResponseWriter, r *http.Request) {
+	cfg.drain.draining.Store(false)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// shutdownServer stops srvs gracefully and closes done when they have all
+// finished. Calls after the first do nothing.
+func shutdownServer(done chan<- struct{}, srvs ...*http.Server) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			defer close(done)
+			ctx, cancel := context.WithTimeout(context.Background(), maxDrainTimeout)
+			defer cancel()
+			var wg sync.WaitGroup
+			for _, srv := range srvs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					srv.Shutdown(ctx)
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/go-chi/chi"
+)
+
+const (
+	exportBatchSize = 500
+
+	exportStatusPending  = "pending"
+	exportStatusComplete = "complete"
+	exportStatusFailed   = "failed"
+
+	exportKindNotes   = "notes"
+	exportKindAccount = "account"
+)
+
+// forEachNoteBatch calls fn with all of the user's notes, oldest first, in
+// batches of exportBatchSize.
+func (cfg *apiConfig) forEachNoteBatch(ctx context.Context, userID string, fn func([]database.Note) error) error {
+	after := database.GetNotesForUserAfterParams{
+		UserID: userID,
+		Limit:  exportBatchSize,
+	}
+	for {
+		notes, err := cfg.DB.GetNotesForUserAfter(ctx, after)
+		if err != nil {
+			return err
+		}
+		if err := fn(notes); err != nil {
+			return err
+		}
+
+		if len(notes) < exportBatchSize {
+			return nil
+		}
+		last := notes[len(notes)-1]
+		after.CreatedAt = last.CreatedAt
+		after.CreatedAt_2 = last.CreatedAt
+		after.ID = last.ID
+	}
+}
+
+func (cfg *apiConfig) handlerNotesExport(w http.ResponseWriter, r *http.Request, user database.User) {
+	w.Header().Set("Content-Disposition", `attachment; filename="notes.json"`)
+	stream, err := newJSONArrayWriter(w, http.StatusOK)
+	if err != nil {
+		log.Printf("Couldn't start export: %v", err)
+		return
+	}
+
+	err = cfg.forEachNoteBatch(r.Context(), user.ID, func(notes []database.Note) error {
+		for _, note := range notes {
+			noteResp, err := databaseNoteToNote(note)
+			if err != nil {
+				return err
+			}
+			if err := stream.Write(noteResp); err != nil {
+				return err
+			}
+		}
+		stream.Flush()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Couldn't export notes, response truncated: %v", err)
+		return
+	}
+
+	if err := stream.Close(); err != nil {
+		log.Printf("Couldn't finish export: %v", err)
+	}
+}
+
+func (cfg *apiConfig) handlerExportsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithNewExport(w, r, user, exportKindNotes)
+}
+
+// respondWithNewExport queues an export job of the given kind and responds
+// with 202 and its status URL.
+func (cfg *apiConfig) respondWithNewExport(w http.ResponseWriter, r *http.Request, user database.User, kind string) {
+	id := cfg.ids.New()
+	err := cfg.DB.CreateExport(r.Context(), database.CreateExportParams{
+		ID:        id,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		Status:    exportStatusPending,
+		Kind:      kind,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_export_failed", err)
+		return
+	}
+
+	export, err := cfg.DB.GetExport(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_export_failed", err)
+		return
+	}
+
+	exportResp, err := databaseExportToExport(export)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_export_failed", err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/exports/"+id)
+	respondWithJSON(w, http.StatusAccepted, exportResp)
+}
+
+func (cfg *apiConfig) getExportForUser(w http.ResponseWriter, r *http.Request, user database.User) (database.GetExportRow, bool) {
+	export, err := cfg.DB.GetExport(r.Context(), chi.URLParam(r, "exportID"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && export.UserID != user.ID) {
+		respondWithError(w, http.StatusNotFound, "export_not_found", nil)
+		return database.GetExportRow{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_export_failed", err)
+		return database.GetExportRow{}, false
+	}
+	return export, true
+}
+
+func (cfg *apiConfig) handlerExportsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	export, ok := cfg.getExportForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	exportResp, err := databaseExportToExport(export)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_export_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, exportResp)
+}
+
+func (cfg *apiConfig) handlerExportsDownload(w http.ResponseWriter, r *http.Request, user database.User) {
+	export, ok := cfg.getExportForUser(w, r, user)
+	if !ok {
+		return
+	}
+	if export.Status != exportStatusComplete {
+		respondWithError(w, http.StatusConflict, "export_is_not_complete", nil)
+		return
+	}
+
+	content, err := cfg.DB.GetExportContent(r.Context(), export.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_export_failed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+export.Kind+`.json"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(content.String))
+}
+
+// runExports processes pending export jobs one at a time until none are left.
+func (cfg *apiConfig) runExports(ctx context.Context) error {
+	for {
+		job, err := cfg.DB.ClaimPendingExport(ctx, time.Now().UTC().Format(time.RFC3339))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		params := database.FinishExportParams{
+			Status:    exportStatusComplete,
+			UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+			ID:        job.ID,
+		}
+		var content string
+		if job.Kind == exportKindAccount {
+			content, err = cfg.buildAccountExport(ctx, job.UserID)
+		} else {
+			content, err = cfg.buildExport(ctx, job.UserID)
+		}
+		if err != nil {
+			log.Printf("Export %s failed: %v", job.ID, err)
+			params.Status = exportStatusFailed
+			params.Error = sql.NullString{String: "Couldn't export " + job.Kind, Valid: true}
+		} else {
+			params.Content = sql.NullString{String: content, Valid: true}
+		}
+
+		if err := cfg.DB.FinishExport(ctx, params); err != nil {
+			return err
+		}
+	}
+}
+
+func (cfg *apiConfig) buildExport(ctx context.Context, userID string) (string, error) {
+	notes := []Note{}
+	err := cfg.forEachNoteBatch(ctx, userID, func(batch []database.Note) error {
+		converted, err := databasePostsToPosts(batch)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, converted...)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dat, err := json.Marshal(notes)
+	if err != nil {
+		return "", err
+	}
+	return string(dat), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// flushRecorder records how much of the body had been written at each
+// flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushedAt []int
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushedAt = append(f.flushedAt, f.Body.Len())
+	f.ResponseRecorder.Flush()
+}
+
+// fakeNotesAfter answers GetNotesForUserAfter from notes, which must be
+// sorted by (created_at, id).
+func fakeNotesAfter(t *testing.T, notes []string) fakeQueryFunc {
+	return func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "name: GetNotesForUserAfter :many") {
+			t.Errorf("unexpected query: %s", query)
+			return noteColumns, nil, nil
+		}
+		afterID, limit := args[3].(string), int(args[4].(int64))
+		start := sort.SearchStrings(notes, afterID)
+		if start < len(notes) && notes[start] == afterID {
+			start++
+		}
+		var rows [][]driver.Value
+		for _, id := range notes[start:min(start+limit, len(notes))] {
+			note := testNote
+			note.ID = id
+			note.Note = strings.Repeat("x", 1000)
+			rows = append(rows, noteRow(note))
+		}
+		return noteColumns, rows, nil
+	}
+}
+
+func TestNotesExportStreamsLargeExports(t *testing.T) {
+	const count = 5000 // about 5 MB of note text
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("note-%06d", i)
+	}
+	cfg := newTestAPIConfig(t, fakeNotesAfter(t, ids))
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	cfg.handlerNotesExport(w, httptest.NewRequest("GET", "/v1/notes/export", nil), testUser)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if size := w.Body.Len(); size < 5<<20 {
+		t.Fatalf("export is %d bytes, want a multi-megabyte export", size)
+	}
+	wantFlushes := count / exportBatchSize
+	if len(w.flushedAt) < wantFlushes {
+		t.Errorf("flushed %d times, want once per batch (%d)", len(w.flushedAt), wantFlushes)
+	}
+	if len(w.flushedAt) > 0 && w.flushedAt[0] >= w.Body.Len()/2 {
+		t.Errorf("first flush came after %d of %d bytes, want the export streamed", w.flushedAt[0], w.Body.Len())
+	}
+
+	var notes []Note
+	if err := json.Unmarshal(w.Body.Bytes(), &notes); err != nil {
+		t.Fatalf("export isn't a JSON array: %v", err)
+	}
+	if len(notes) != count {
+		t.Fatalf("exported %d notes, want %d", len(notes), count)
+	}
+	for i, note := range notes {
+		if note.ID != ids[i] {
+			t.Fatalf("note %d is %s, want %s", i, note.ID, ids[i])
+		}
+	}
+}
+
+func TestNotesExportEmpty(t *testing.T) {
+	cfg := newTestAPIConfig(t, fakeNotesAfter(t, nil))
+
+	w := httptest.NewRecorder()
+	cfg.handlerNotesExport(w, httptest.NewRequest("GET", "/v1/notes/export", nil), testUser)
+
+	var notes []Note
+	if err := json.Unmarshal(w.Body.Bytes(), &notes); err != nil {
+		t.Fatalf("export %q isn't a JSON array: %v", w.Body, err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("exported %d notes, want none", len(notes))
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	graphLabelLength = 40
+	maxGraphDepth    = 10
+)
+
+type graphNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type graphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type noteGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+func noteLabel(text string) string {
+	label, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(label); len(runes) > graphLabelLength {
+		label = string(runes[:graphLabelLength]) + "…"
+	}
+	return label
+}
+
+// neighbourhood returns the IDs within depth hops of root, following edges
+// in either direction.
+func neighbourhood(root string, depth int, edges []graphEdge) map[string]bool {
+	adjacent := map[string][]string{}
+	for _, edge := range edges {
+		adjacent[edge.Source] = append(adjacent[edge.Source], edge.Target)
+		adjacent[edge.Target] = append(adjacent[edge.Target], edge.Source)
+	}
+
+	seen := map[string]bool{root: true}
+	frontier := []string{root}
+	for i := 0; i < depth && len(frontier) > 0; i++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbour := range adjacent[id] {
+				if !seen[neighbour] {
+					seen[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+	return seen
+}
+
+func (g noteGraph) dot() string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph notes {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s];\n", quote(node.ID), quote(node.Label))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", quote(edge.Source), quote(edge.Target))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (cfg *apiConfig) handlerNotesGraphGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	if query.Has("tag") {
+		respondWithError(w, http.StatusBadRequest, "tag_filter_unsupported", nil)
+		return
+	}
+	root := query.Get("root")
+	depth := 1
+	if query.Has("depth") {
+		var err error
+		depth, err = strconv.Atoi(query.Get("depth"))
+		if err != nil || depth < 0 || depth > maxGraphDepth {
+			respondWithErrorDetail(w, http.StatusBadRequest, "invalid_depth", strconv.Itoa(maxGraphDepth), nil)
+			return
+		}
+	}
+
+	notes, err := cfg.DB.GetNotesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+		return
+	}
+	links, err := cfg.DB.GetNoteLinksForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_links_failed", err)
+		return
+	}
+
+	graph := noteGraph{Nodes: []graphNode{}, Edges: []graphEdge{}}
+	for _, link := range links {
+		graph.Edges = append(graph.Edges, graphEdge{
+			Source: link.SourceNoteID,
+			Target: link.TargetNoteID,
+			Type:   "link",
+		})
+	}
+
+	var include map[string]bool
+	if root != "" {
+		include = neighbourhood(root, depth, graph.Edges)
+	}
+	for _, note := range notes {
+		if include == nil || include[note.ID] {
+			graph.Nodes = append(graph.Nodes, graphNode{ID: note.ID, Label: noteLabel(note.Note)})
+		}
+	}
+	if include != nil {
+		if len(graph.Nodes) == 0 {
+			respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+			return
+		}
+		edges := []graphEdge{}
+		for _, edge := range graph.Edges {
+			if include[edge.Source] && include[edge.Target] {
+				edges = append(edges, edge)
+			}
+		}
+		graph.Edges = edges
+	}
+
+	if query.Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(graph.dot()))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, graph)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	legalHoldUser = "user"
+	legalHoldNote = "note"
+)
+
+func (cfg *apiConfig) handlerLegalHoldsGet(w http.ResponseWriter, r *http.Request) {
+	type legalHold struct {
+		SubjectType string    `json:"subject_type"`
+		SubjectID   string    `json:"subject_id"`
+		CreatedAt   time.Time `json:"created_at"`
+		Reason      string    `json:"reason"`
+	}
+
+	holds, err := cfg.DB.GetLegalHolds(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_legal_holds_failed", err)
+		return
+	}
+
+	resp := make([]legalHold, len(holds))
+	for i, hold := range holds {
+		createdAt, err := parseDBTime(hold.CreatedAt)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "parse_created_date_failed", err)
+			return
+		}
+		resp[i] = legalHold{
+			SubjectType: hold.SubjectType,
+			SubjectID:   hold.SubjectID,
+			CreatedAt:   createdAt,
+			Reason:      hold.Reason,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerLegalHoldApply(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason string `json:"reason"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "reason_is_required", nil)
+		return
+	}
+
+	subjectType := chi.URLParam(r, "subjectType")
+	subjectID := chi.URLParam(r, "subjectID")
+	switch subjectType {
+	case legalHoldUser:
+		_, err = cfg.DB.GetUserByID(r.Context(), subjectID)
+	case legalHoldNote:
+		_, err = cfg.DB.GetNote(r.Context(), subjectID)
+	default:
+		respondWithError(w, http.StatusBadRequest, "invalid_legal_hold_subject", nil)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "subject_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_subject_failed", err)
+		return
+	}
+
+	var created bool
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.CreateLegalHold(r.Context(), database.CreateLegalHoldParams{
+			SubjectType: subjectType,
+			SubjectID:   subjectID,
+			CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+			Reason:      params.Reason,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		created = true
+		return recordAudit(r.Context(), q, auditActorAdmin, "legal_hold.applied", subjectType, subjectID, params)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "apply_legal_hold_failed", err)
+		return
+	}
+
+	if !created {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (cfg *apiConfig) handlerLegalHoldLift(w http.ResponseWriter, r *http.Request) {
+	subjectType := chi.URLParam(r, "subjectType")
+	subjectID := chi.URLParam(r, "subjectID")
+
+	var found bool
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.DeleteLegalHold(r.Context(), database.DeleteLegalHoldParams{
+			SubjectType: subjectType,
+			SubjectID:   subjectID,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		found = true
+		return recordAudit(r.Context(), q, auditActorAdmin, "legal_hold.lifted", subjectType, subjectID, struct{}{})
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "lift_legal_hold_failed", err)
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "legal_hold_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
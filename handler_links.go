@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+var noteLinkPattern = regexp.MustCompile(`\[\[([^\[\]\s]+)\]\]`)
+
+// parseNoteLinks returns the distinct note IDs referenced as [[note-id]].
+func parseNoteLinks(text string) []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, match := range noteLinkPattern.FindAllStringSubmatch(text, -1) {
+		if id := match[1]; !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// saveNoteLinks records the links in note. Links to notes that don't exist or
+// belong to someone else are ignored.
+func saveNoteLinks(ctx context.Context, q *database.Queries, note database.Note) error {
+	for _, targetID := range parseNoteLinks(note.Note) {
+		if targetID == note.ID {
+			continue
+		}
+		target, err := q.GetNote(ctx, targetID)
+		if err != nil || target.UserID != note.UserID {
+			continue
+		}
+
+		err = q.CreateNoteLink(ctx, database.CreateNoteLinkParams{
+			SourceNoteID: note.ID,
+			TargetNoteID: target.ID,
+			CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerNotesBacklinksGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	notes, err := cfg.DB.GetBacklinksForUser(r.Context(), database.GetBacklinksForUserParams{
+		TargetNoteID: note.ID,
+		UserID:       user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_backlinks_failed", err)
+		return
+	}
+
+	notesResp, err := databasePostsToPosts(notes)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_notes_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, notesResp)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+const maintenanceSettingKey = "maintenance_mode"
+
+type maintenanceState struct {
+	Enabled           bool   `json:"enabled"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+// loadMaintenanceState restores the maintenance flag saved in the database,
+// so it survives restarts.
+func (cfg *apiConfig) loadMaintenanceState(ctx context.Context) error {
+	setting, err := cfg.DB.GetSetting(ctx, maintenanceSettingKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		cfg.maintenance.Store(&maintenanceState{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	state := maintenanceState{}
+	if err := json.Unmarshal([]byte(setting.Value), &state); err != nil {
+		return err
+	}
+	cfg.maintenance.Store(&state)
+	return nil
+}
+
+func (cfg *apiConfig) handlerMaintenanceGet(w http.ResponseWriter, r *http.Request) {
+	state := cfg.maintenance.Load()
+	if state == nil {
+		state = &maintenanceState{}
+	}
+	respondWithJSON(w, http.StatusOK, state)
+}
+
+func (cfg *apiConfig) handlerMaintenanceUpdate(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	state := maintenanceState{}
+	err := decoder.Decode(&state)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if state.RetryAfterSeconds < 0 {
+		respondWithError(w, http.StatusBadRequest, "negative_retry_after", nil)
+		return
+	}
+	if state.Enabled && state.Message == "" {
+		state.Message = i18n.Message("maintenance")
+	}
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "encode_maintenance_state_failed", err)
+		return
+	}
+	err = cfg.DB.UpsertSetting(r.Context(), database.UpsertSettingParams{
+		Key:       maintenanceSettingKey,
+		Value:     string(value),
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "save_maintenance_state_failed", err)
+		return
+	}
+
+	cfg.maintenance.Store(&state)
+	respondWithJSON(w, http.StatusOK, state)
+}
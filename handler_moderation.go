@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+	"github.com/bootdotdev/learn-cicd-starter/internal/moderation"
+)
+
+const (
+	moderationFlag   = "flag"
+	moderationReject = "reject"
+)
+
+type noteRejectedError struct {
+	reason string
+}
+
+func (e *noteRejectedError) Error() string {
+	code, detail := e.errorCode()
+	return i18n.Message(code) + detail
+}
+
+func (e *noteRejectedError) errorCode() (code, detail string) {
+	if e.reason == "" {
+		return "note_rejected", ""
+	}
+	return "note_rejected_reason", e.reason
+}
+
+// moderationActionFromEnv reads MODERATION_ACTION, which defaults to flag.
+func moderationActionFromEnv() (string, error) {
+	switch action := os.Getenv("MODERATION_ACTION"); action {
+	case "":
+		return moderationFlag, nil
+	case moderationFlag, moderationReject:
+		return action, nil
+	default:
+		return "", fmt.Errorf("MODERATION_ACTION must be %q or %q", moderationFlag, moderationReject)
+	}
+}
+
+// moderate checks text with the configured moderator. A failing moderator
+// lets the note through rather than blocking every write.
+func (cfg *apiConfig) moderate(ctx context.Context, text string) moderation.Verdict {
+	if cfg.moderator == nil {
+		return moderation.Verdict{}
+	}
+	verdict, err := cfg.moderator.Check(ctx, text)
+	if err != nil {
+		log.Printf("Moderation check failed, allowing note: %v", err)
+		return moderation.Verdict{}
+	}
+	return verdict
+}
+
+// moderateNote checks a note's title along with its text, in one call. title
+// is empty when it's derived from text, which is checked anyway.
+func (cfg *apiConfig) moderateNote(ctx context.Context, title, text string) moderation.Verdict {
+	if title != "" {
+		text = title + "\n\n" + text
+	}
+	return cfg.moderate(ctx, text)
+}
+
+func (cfg *apiConfig) handlerModerationQueueGet(w http.ResponseWriter, r *http.Request) {
+	type flaggedNote struct {
+		NoteID    string    `json:"note_id"`
+		UserID    string    `json:"user_id"`
+		Note      string    `json:"note"`
+		Reason    string    `json:"reason"`
+		FlaggedAt time.Time `json:"flagged_at"`
+	}
+
+	flagged, err := cfg.DB.GetFlaggedNotes(r.Context(), maxPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_flagged_notes_failed", err)
+		return
+	}
+
+	resp := make([]flaggedNote, len(flagged))
+	for i, f := range flagged {
+		flaggedAt, err := parseDBTime(f.FlaggedAt)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "parse_flagged_date_failed", err)
+			return
+		}
+		resp[i] = flaggedNote{
+			NoteID:    f.ID,
+			UserID:    f.UserID,
+			Note:      f.Note,
+			Reason:    f.Reason,
+			FlaggedAt: flaggedAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerModerationFlagDelete clears a flag once a moderator has reviewed the
+// note.
+func (cfg *apiConfig) handlerModerationFlagDelete(w http.ResponseWriter, r *http.Request) {
+	n, err := cfg.DB.DeleteModerationFlag(r.Context(), chi.URLParam(r, "noteID"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_moderation_flag_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "flag_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/moderation"
+)
+
+// blockWord flags any text containing word.
+type blockWord string
+
+func (b blockWord) Check(_ context.Context, text string) (moderation.Verdict, error) {
+	if strings.Contains(text, string(b)) {
+		return moderation.Verdict{Flagged: true, Reason: "blocked word"}, nil
+	}
+	return moderation.Verdict{}, nil
+}
+
+func TestModerationChecksTitles(t *testing.T) {
+	cfg := newTestAPIConfig(t, func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+		return nil, nil, errors.New("moderation should have rejected the note first")
+	})
+	cfg.moderator = blockWord("spam")
+	cfg.moderationAction = moderationReject
+
+	var rejected *noteRejectedError
+	_, _, err := cfg.createNote(context.Background(), testUser.ID, "Buy spam", "Buy milk")
+	if !errors.As(err, &rejected) {
+		t.Errorf("createNote with a blocked title = %v, want a rejection", err)
+	}
+	title := "Buy spam"
+	_, _, err = cfg.updateNote(context.Background(), testNote, &title, "Buy milk")
+	if !errors.As(err, &rejected) {
+		t.Errorf("updateNote with a blocked title = %v, want a rejection", err)
+	}
+}
@@ -1,66 +1,537 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bootdotdev/learn-cicd-starter/internal/database"
-	"github.com/google/uuid"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+	"github.com/go-chi/chi"
+	"golang.org/x/text/unicode/norm"
 )
 
 func (cfg *apiConfig) handlerNotesGet(w http.ResponseWriter, r *http.Request, user database.User) {
-	posts, err := cfg.DB.GetNotesForUser(r.Context(), user.ID)
+	page, paginate, err := parsePageParams(r.URL.Query())
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't get posts for user", err)
+		respondWithAPIError(w, http.StatusBadRequest, err)
 		return
 	}
+	if hasNoteFilters(r.URL.Query()) {
+		if paginate {
+			respondWithError(w, http.StatusBadRequest, "filters_with_pagination", nil)
+			return
+		}
+		cfg.handlerNotesFilter(w, r, user)
+		return
+	}
+	if paginate {
+		cfg.handlerNotesGetPage(w, r, user, page)
+		return
+	}
+
+	// Identical concurrent list requests share one query. It runs detached
+	// from this request, so one client hanging up doesn't fail the others.
+	// While the database is unavailable, the last list read is served.
+	ctx := context.WithoutCancel(r.Context())
+	posts, stale, err := cfg.staleNoteLists.read(user.ID, func() ([]database.Note, error) {
+		posts, err, _ := cfg.noteLists.Do(user.ID, func() ([]database.Note, error) {
+			return cfg.DB.GetNotesForUser(ctx, user.ID)
+		})
+		return posts, err
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_posts_for_user_failed", err)
+		return
+	}
+
+	if stale {
+		markStale(w)
+	}
+	cfg.respondWithNotes(w, r, user, posts)
+	if !stale {
+		cfg.shadowNotesList(user.ID, posts)
+	}
+}
+
+// respondWithNotes converts notes for the response, adding the extras asked
+// for with ?include=.
+func (cfg *apiConfig) respondWithNotes(w http.ResponseWriter, r *http.Request, user database.User, notes []database.Note) {
+	notesResp, err := databasePostsToPosts(notes)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_notes_failed", err)
+		return
+	}
+
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch include {
+		case "":
+		case "reactions":
+			if err := cfg.attachReactions(r.Context(), user.ID, notesResp); err != nil {
+				respondWithError(w, http.StatusInternalServerError, "get_reactions_failed", err)
+				return
+			}
+		default:
+			respondWithErrorDetail(w, http.StatusBadRequest, "unknown_include", include, nil)
+			return
+		}
+	}
+
+	respondWithFields(w, r, http.StatusOK, notesResp)
+}
+
+// handlerNotesGetPage serves one page of notes using keyset pagination. The
+// cursor for the following page, if any, is returned in the X-Next-Cursor
+// header so the response body stays a plain array.
+func (cfg *apiConfig) handlerNotesGetPage(w http.ResponseWriter, r *http.Request, user database.User, page pageParams) {
+	notes, err := cfg.DB.GetNotesForUserAfter(r.Context(), database.GetNotesForUserAfterParams{
+		UserID:      user.ID,
+		CreatedAt:   page.Cursor.CreatedAt,
+		CreatedAt_2: page.Cursor.CreatedAt,
+		ID:          page.Cursor.ID,
+		Limit:       int64(page.Limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+		return
+	}
+
+	if len(notes) == page.Limit {
+		last := notes[len(notes)-1]
+		w.Header().Set("X-Next-Cursor", noteCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode())
+	}
+	cfg.respondWithNotes(w, r, user, notes)
+}
+
+// hasNoteFilters reports whether query asks for any of the filters served by
+// handlerNotesFilter.
+func hasNoteFilters(query url.Values) bool {
+	for _, name := range []string{"ids", "lang", "title", "due_before"} {
+		if query.Has(name) {
+			return true
+		}
+	}
+	return notePropertyFilters(query) != nil
+}
 
-	postsResp, err := databasePostsToPosts(posts)
+// parseNoteFilters turns ?ids=, ?lang=, ?title=, ?due_before= and ?prop.name=
+// into the parameters of one FilterNotesForUser query, so that any of them
+// can be combined. It also returns the requested IDs, in order.
+func parseNoteFilters(query url.Values, userID string) (database.FilterNotesForUserParams, []string, error) {
+	params := database.FilterNotesForUserParams{UserID: userID, Properties: "{}"}
+
+	var ids []string
+	if query.Has("ids") {
+		seen := map[string]bool{}
+		for _, id := range strings.Split(query.Get("ids"), ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 {
+			return params, nil, &apiError{code: "ids_required"}
+		}
+		if len(ids) > maxPageSize {
+			return params, nil, &apiError{code: "too_many_ids", detail: strconv.Itoa(maxPageSize)}
+		}
+		encoded, err := json.Marshal(ids)
+		if err != nil {
+			return params, nil, err
+		}
+		params.Ids = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	if query.Has("lang") {
+		lang := strings.ToLower(query.Get("lang"))
+		if !validLanguageCode(lang) {
+			return params, nil, &apiError{code: "invalid_lang"}
+		}
+		params.Language = sql.NullString{String: lang, Valid: true}
+	}
+
+	if query.Has("title") {
+		title := strings.TrimSpace(query.Get("title"))
+		if title == "" {
+			return params, nil, &apiError{code: "empty_title_search"}
+		}
+		params.Title = sql.NullString{String: likePattern(norm.NFC.String(title)), Valid: true}
+	}
+
+	if query.Has("due_before") {
+		dueBefore, err := time.Parse(time.RFC3339, query.Get("due_before"))
+		if err != nil {
+			return params, nil, &apiError{code: "invalid_due_before"}
+		}
+		params.DueBefore = sql.NullString{String: dueBefore.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	if filters := notePropertyFilters(query); filters != nil {
+		for name := range filters {
+			if !validPropertyName(name) {
+				return params, nil, &apiError{code: "invalid_property_name", detail: name}
+			}
+		}
+		encoded, err := json.Marshal(filters)
+		if err != nil {
+			return params, nil, err
+		}
+		params.Properties = string(encoded)
+		// project has an index of its own; see notes_user_property_project_idx.
+		if project, ok := filters["project"]; ok {
+			params.Project = sql.NullString{String: project, Valid: true}
+		}
+	}
+
+	return params, ids, nil
+}
+
+// handlerNotesFilter lists the notes matching all of the filters asked for,
+// newest first, or soonest due first with ?due_before=. With ?ids=a,b,c the
+// notes are returned in the order asked for instead, and IDs that don't
+// match are listed in the X-Missing-Note-IDs header, so the body stays a
+// plain array.
+func (cfg *apiConfig) handlerNotesFilter(w http.ResponseWriter, r *http.Request, user database.User) {
+	params, ids, err := parseNoteFilters(r.URL.Query(), user.ID)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	notes, err := cfg.DB.FilterNotesForUser(r.Context(), params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't convert posts", err)
+		respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+		return
+	}
+	if ids == nil {
+		cfg.respondWithNotes(w, r, user, notes)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, postsResp)
+	byID := make(map[string]database.Note, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+	notes = notes[:0]
+	var missing []string
+	for _, id := range ids {
+		note, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	if len(missing) > 0 {
+		w.Header().Set("X-Missing-Note-IDs", strings.Join(missing, ","))
+	}
+	cfg.respondWithNotes(w, r, user, notes)
 }
 
 func (cfg *apiConfig) handlerNotesCreate(w http.ResponseWriter, r *http.Request, user database.User) {
 	type parameters struct {
-		Note string `json:"note"`
+		Title string `json:"title"`
+		Note  string `json:"note"`
 	}
-	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
-	err := decoder.Decode(&params)
+	err := decodeNoteParameters(r, &params)
+	if errors.Is(err, errNoteInvalidUTF8) {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
 		return
 	}
 
-	id := uuid.New().String()
-	err = cfg.DB.CreateNote(r.Context(), database.CreateNoteParams{
-		ID:        id,
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
-		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
-		Note:      params.Note,
-		UserID:    user.ID,
+	cfg.respondWithNewNote(w, r, user, params.Title, params.Note)
+}
+
+// createNote stores a new note owned by userID, titled title or, if that's
+// empty, with a title derived from text. Every code path that creates notes
+// goes through here. When sanitization is enabled it also returns what was
+// stripped from the title and text.
+func (cfg *apiConfig) createNote(ctx context.Context, userID, title, text string) (database.Note, []string, error) {
+	text, err := normalizeNoteText(text)
+	if err != nil {
+		return database.Note{}, nil, err
+	}
+	title, err = normalizeNoteTitle(title)
+	if err != nil {
+		return database.Note{}, nil, err
+	}
+	var removed []string
+	if cfg.runtime.Load().SanitizeNotes {
+		title, text, removed = sanitizeNote(title, text)
+	}
+	storedTitle, customTitle := storedNoteTitle(title, text)
+
+	if err := cfg.checkNewAccountPolicy(ctx, userID); err != nil {
+		return database.Note{}, nil, err
+	}
+	if err := cfg.checkPlanQuota(ctx, userID); err != nil {
+		return database.Note{}, nil, err
+	}
+
+	verdict := cfg.moderateNote(ctx, title, text)
+	if verdict.Flagged && cfg.moderationAction == moderationReject {
+		return database.Note{}, nil, &noteRejectedError{reason: verdict.Reason}
+	}
+
+	var note database.Note
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		slug, err := newNoteSlug(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		id := cfg.ids.New()
+		err = q.CreateNote(ctx, database.CreateNoteParams{
+			ID:          id,
+			CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+			UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
+			Note:        text,
+			UserID:      userID,
+			Slug:        slug,
+			Language:    noteLanguage(text),
+			Title:       storedTitle,
+			TitleCustom: customTitle,
+		})
+		if err != nil {
+			return err
+		}
+
+		note, err = q.GetNote(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := saveNoteLinks(ctx, q, note); err != nil {
+			return err
+		}
+		if verdict.Flagged {
+			err := q.CreateModerationFlag(ctx, database.CreateModerationFlagParams{
+				NoteID:    note.ID,
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+				Reason:    verdict.Reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return enqueueEvent(ctx, q, events.New(events.NoteCreated, note.UserID, note.ID))
 	})
+	return note, removed, err
+}
+
+// updateNote replaces the text of note, applying the same sanitization,
+// moderation and link parsing as createNote. A nil title keeps a title the
+// note was given, or derives a new one from text if it was derived; an empty
+// one goes back to deriving it. It fails with errNoteVersionConflict unless
+// the stored note is still at note.Version.
+func (cfg *apiConfig) updateNote(ctx context.Context, note database.Note, title *string, text string) (database.Note, []string, error) {
+	text, err := normalizeNoteText(text)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create note", err)
-		return
+		return database.Note{}, nil, err
+	}
+	newTitle := ""
+	if title != nil {
+		newTitle, err = normalizeNoteTitle(*title)
+		if err != nil {
+			return database.Note{}, nil, err
+		}
+	} else if note.TitleCustom {
+		newTitle = note.Title.String
+	}
+	var removed []string
+	if cfg.runtime.Load().SanitizeNotes {
+		newTitle, text, removed = sanitizeNote(newTitle, text)
+	}
+	storedTitle, customTitle := storedNoteTitle(newTitle, text)
+
+	verdict := cfg.moderateNote(ctx, newTitle, text)
+	if verdict.Flagged && cfg.moderationAction == moderationReject {
+		return database.Note{}, nil, &noteRejectedError{reason: verdict.Reason}
 	}
 
-	note, err := cfg.DB.GetNote(r.Context(), id)
+	var updated database.Note
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		n, err := q.UpdateNote(ctx, database.UpdateNoteParams{
+			Note:        text,
+			Language:    noteLanguage(text),
+			Title:       storedTitle,
+			TitleCustom: customTitle,
+			UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
+			ID:          note.ID,
+			Version:     note.Version,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errNoteVersionConflict
+		}
+
+		updated, err = q.GetNote(ctx, note.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteNoteLinksFromSource(ctx, note.ID); err != nil {
+			return err
+		}
+		if err := saveNoteLinks(ctx, q, updated); err != nil {
+			return err
+		}
+		if verdict.Flagged {
+			err := q.CreateModerationFlag(ctx, database.CreateModerationFlagParams{
+				NoteID:    note.ID,
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+				Reason:    verdict.Reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return enqueueEvent(ctx, q, events.New(events.NoteUpdated, note.UserID, note.ID))
+	})
+	return updated, removed, err
+}
+
+// respondWithNewNote creates a note owned by user and responds with it.
+func (cfg *apiConfig) respondWithNewNote(w http.ResponseWriter, r *http.Request, user database.User, title, text string) {
+	note, removed, err := cfg.createNote(r.Context(), user.ID, title, text)
+	var invalid *noteTextError
+	if errors.As(err, &invalid) {
+		respondWithAPIError(w, http.StatusBadRequest, invalid)
+		return
+	}
+	var rejected *noteRejectedError
+	if errors.As(err, &rejected) {
+		respondWithAPIError(w, http.StatusUnprocessableEntity, rejected)
+		return
+	}
+	var throttled *noteThrottledError
+	if errors.As(err, &throttled) {
+		respondWithAPIError(w, http.StatusTooManyRequests, throttled)
+		return
+	}
+	var overLimit *planLimitError
+	if errors.As(err, &overLimit) {
+		respondWithAPIError(w, http.StatusForbidden, overLimit)
+		return
+	}
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Couldn't get note", err)
+		respondWithError(w, http.StatusInternalServerError, "create_note_failed", err)
 		return
 	}
 
 	noteResp, err := databaseNoteToNote(note)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't convert note", err)
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
 		return
 	}
+	noteResp.RemovedContent = removed
 
 	respondWithJSON(w, http.StatusCreated, noteResp)
 }
+
+// getNoteForUser looks up a note by the noteID URL parameter, responding with
+// 404 if it doesn't exist or belongs to someone else.
+func (cfg *apiConfig) getNoteForUser(w http.ResponseWriter, r *http.Request, user database.User) (database.Note, bool) {
+	note, err := cfg.DB.GetNote(r.Context(), chi.URLParam(r, "noteID"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && note.UserID != user.ID) {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return database.Note{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+		return database.Note{}, false
+	}
+	return note, true
+}
+
+func (cfg *apiConfig) handlerNotesDuplicate(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	// Only a title the note was given is copied; a derived one would be
+	// derived again anyway.
+	title := ""
+	if note.TitleCustom {
+		title = note.Title.String
+	}
+	cfg.respondWithNewNote(w, r, user, title, note.Note)
+}
+
+const bulkDeleteBatchSize = 500
+
+func (cfg *apiConfig) handlerNotesBulkDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	if query.Get("confirm") != "true" {
+		respondWithError(w, http.StatusBadRequest, "bulk_delete_requires_confirm", nil)
+		return
+	}
+	if query.Has("tag") {
+		respondWithError(w, http.StatusBadRequest, "tag_filter_unsupported", nil)
+		return
+	}
+	if query.Get("created_before") == "" {
+		respondWithError(w, http.StatusBadRequest, "bulk_delete_requires_created_before", nil)
+		return
+	}
+	createdBefore, err := time.Parse(time.RFC3339, query.Get("created_before"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid_created_before", err)
+		return
+	}
+
+	onHold, err := cfg.DB.HasLegalHold(r.Context(), database.HasLegalHoldParams{
+		SubjectType: legalHoldUser,
+		SubjectID:   user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "check_legal_hold_failed", err)
+		return
+	}
+	if onHold != 0 {
+		respondWithError(w, http.StatusConflict, "account_on_legal_hold", nil)
+		return
+	}
+
+	// Deleting in batches keeps each write transaction short, so other
+	// writers aren't locked out of the database for the whole operation.
+	// Notes under legal hold are skipped.
+	var deleted int64
+	for {
+		n, err := cfg.DB.DeleteNotesForUserBefore(r.Context(), database.DeleteNotesForUserBeforeParams{
+			UserID:    user.ID,
+			CreatedAt: createdBefore.UTC().Format(time.RFC3339),
+			Limit:     bulkDeleteBatchSize,
+		})
+		deleted += n
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "delete_notes_failed", err)
+			return
+		}
+		if n < bulkDeleteBatchSize {
+			break
+		}
+	}
+
+	type response struct {
+		Deleted int64 `json:"deleted"`
+	}
+	respondWithJSON(w, http.StatusOK, response{Deleted: deleted})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotesGetCollapsesConcurrentReads(t *testing.T) {
+	var queries atomic.Int32
+	release := make(chan struct{})
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "name: GetNotesForUser :many") {
+			t.Errorf("unexpected query: %s", query)
+			return noteColumns, nil, nil
+		}
+		queries.Add(1)
+		<-release
+		return noteColumns, [][]driver.Value{noteRow(testNote)}, nil
+	})
+
+	const clients = 20
+	var started, done sync.WaitGroup
+	started.Add(clients)
+	done.Add(clients)
+	responses := make([]*httptest.ResponseRecorder, clients)
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+		go func(w *httptest.ResponseRecorder) {
+			defer done.Done()
+			started.Done()
+			cfg.handlerNotesGet(w, httptest.NewRequest("GET", "/v1/notes", nil), testUser)
+		}(responses[i])
+	}
+	started.Wait()
+	// Give every request time to join the query in flight before it returns.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if n := queries.Load(); n != 1 {
+		t.Errorf("%d concurrent list requests ran %d queries, want 1", clients, n)
+	}
+	for i, w := range responses {
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, body %s", i, w.Code, w.Body)
+		}
+		var notes []Note
+		if err := json.NewDecoder(w.Body).Decode(&notes); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if len(notes) != 1 || notes[0].ID != testNote.ID {
+			t.Errorf("request %d got %+v, want the one test note", i, notes)
+		}
+	}
+}
+
+func TestNotesGetCombinesFilters(t *testing.T) {
+	other := testNote
+	other.ID = "0190d6d4-7ac4-7b3e-9d67-3a2a8d8c4f02"
+	var gotArgs []driver.Value
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "name: FilterNotesForUser :many") {
+			t.Errorf("unexpected query: %s", query)
+			return noteColumns, nil, nil
+		}
+		gotArgs = args
+		return noteColumns, [][]driver.Value{noteRow(testNote), noteRow(other)}, nil
+	})
+
+	w := httptest.NewRecorder()
+	target := "/v1/notes?ids=" + other.ID + ",missing," + testNote.ID + "&lang=EN&title=milk&prop.project=alpha&prop.status=open"
+	cfg.handlerNotesGet(w, httptest.NewRequest("GET", target, nil), testUser)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+
+	want := []driver.Value{
+		testUser.ID,
+		`["` + other.ID + `","missing","` + testNote.ID + `"]`,
+		"en",
+		"%milk%",
+		nil,
+		"alpha",
+		`{"project":"alpha","status":"open"}`,
+	}
+	if len(gotArgs) != len(want) {
+		t.Fatalf("query args = %v, want %v", gotArgs, want)
+	}
+	for i := range want {
+		if gotArgs[i] != want[i] {
+			t.Errorf("query arg %d = %v, want %v", i+1, gotArgs[i], want[i])
+		}
+	}
+
+	var notes []Note
+	if err := json.NewDecoder(w.Body).Decode(&notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].ID != other.ID || notes[1].ID != testNote.ID {
+		t.Errorf("got %+v, want the two notes in the order asked for", notes)
+	}
+	if missing := w.Header().Get("X-Missing-Note-IDs"); missing != "missing" {
+		t.Errorf("X-Missing-Note-IDs = %q, want %q", missing, "missing")
+	}
+}
+
+func TestNotesGetRejectsFiltersWithPagination(t *testing.T) {
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		t.Errorf("unexpected query: %s", query)
+		return noteColumns, nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	cfg.handlerNotesGet(w, httptest.NewRequest("GET", "/v1/notes?lang=en&limit=10", nil), testUser)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "filters_with_pagination") {
+		t.Errorf("status %d, body %s; want 400 filters_with_pagination", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	promoCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	promoCodeLength   = 10
+)
+
+var (
+	promoCodePattern = regexp.MustCompile(`^[A-Z0-9-]{4,32}$`)
+
+	errPromoAlreadyRedeemed = errors.New("promo code already redeemed")
+	errPromoUnavailable     = errors.New("promo code expired or used up")
+)
+
+type promoCode struct {
+	Code       string     `json:"code"`
+	Plan       string     `json:"plan,omitempty"`
+	BonusNotes int64      `json:"bonus_notes"`
+	MaxUses    int64      `json:"max_uses"`
+	Uses       int64      `json:"uses"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func databasePromoCodeToPromoCode(code database.PromoCode) (promoCode, error) {
+	createdAt, err := parseDBTime(code.CreatedAt)
+	if err != nil {
+		return promoCode{}, err
+	}
+	var expiresAt *time.Time
+	if code.ExpiresAt.Valid {
+		t, err := parseDBTime(code.ExpiresAt.String)
+		if err != nil {
+			return promoCode{}, err
+		}
+		expiresAt = &t
+	}
+	return promoCode{
+		Code:       code.Code,
+		Plan:       code.Plan.String,
+		BonusNotes: code.BonusNotes,
+		MaxUses:    code.MaxUses,
+		Uses:       code.Uses,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+// normalizePromoCode makes codes case-insensitive and forgiving of stray
+// whitespace when typed in.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func generatePromoCode() (string, error) {
+	b := make([]byte, promoCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = promoCodeAlphabet[int(b[i])%len(promoCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// handlerPromoCodesCreate mints a promo code granting a plan, bonus notes on
+// top of the plan's note limit, or both. Without a code in the request one is
+// generated.
+func (cfg *apiConfig) handlerPromoCodesCreate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Code       string     `json:"code"`
+		Plan       string     `json:"plan"`
+		BonusNotes int64      `json:"bonus_notes"`
+		MaxUses    int64      `json:"max_uses"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case params.Plan == "" && params.BonusNotes == 0:
+		respondWithError(w, http.StatusBadRequest, "promo_code_grants_nothing", nil)
+		return
+	case params.BonusNotes < 0 || params.MaxUses < 0:
+		respondWithError(w, http.StatusBadRequest, "negative_promo_limits", nil)
+		return
+	case params.ExpiresAt != nil && !params.ExpiresAt.After(now):
+		respondWithError(w, http.StatusBadRequest, "expires_at_in_past", nil)
+		return
+	}
+	if params.Plan != "" {
+		_, err := cfg.DB.GetPlan(r.Context(), params.Plan)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusBadRequest, "unknown_plan", nil)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "get_plan_failed", err)
+			return
+		}
+	}
+
+	code := normalizePromoCode(params.Code)
+	if code == "" {
+		code, err = generatePromoCode()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "generate_promo_code_failed", err)
+			return
+		}
+	} else if !promoCodePattern.MatchString(code) {
+		respondWithError(w, http.StatusBadRequest, "invalid_promo_code_format", nil)
+		return
+	}
+
+	var expiresAt sql.NullString
+	if params.ExpiresAt != nil {
+		expiresAt = sql.NullString{String: params.ExpiresAt.UTC().Format(time.RFC3339), Valid: true}
+	}
+	var created bool
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.CreatePromoCode(r.Context(), database.CreatePromoCodeParams{
+			Code:       code,
+			Plan:       sql.NullString{String: params.Plan, Valid: params.Plan != ""},
+			BonusNotes: params.BonusNotes,
+			MaxUses:    params.MaxUses,
+			ExpiresAt:  expiresAt,
+			CreatedAt:  now.Format(time.RFC3339),
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		created = true
+		return recordAudit(r.Context(), q, auditActorAdmin, "promo_code.created", "promo_code", code, params)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_promo_code_failed", err)
+		return
+	}
+	if !created {
+		respondWithError(w, http.StatusConflict, "promo_code_already_exists", nil)
+		return
+	}
+
+	dbCode, err := cfg.DB.GetPromoCode(r.Context(), code)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_promo_code_failed", err)
+		return
+	}
+	resp, err := databasePromoCodeToPromoCode(dbCode)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_promo_code_failed", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (cfg *apiConfig) handlerPromoCodesGet(w http.ResponseWriter, r *http.Request) {
+	codes, err := cfg.DB.GetPromoCodes(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_promo_codes_failed", err)
+		return
+	}
+
+	resp := make([]promoCode, len(codes))
+	for i, code := range codes {
+		resp[i], err = databasePromoCodeToPromoCode(code)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "convert_promo_code_failed", err)
+			return
+		}
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerBillingRedeem applies a promo code to the user: its plan, if any,
+// replaces theirs, and its bonus notes are added to what they already have.
+// Each user can redeem a code once. Responds with the user's plan as
+// handlerPlanGet does.
+func (cfg *apiConfig) handlerBillingRedeem(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Code string `json:"code"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	code := normalizePromoCode(params.Code)
+	if code == "" {
+		respondWithError(w, http.StatusBadRequest, "code_is_required", nil)
+		return
+	}
+
+	promo, err := cfg.DB.GetPromoCode(r.Context(), code)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "unknown_promo_code", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_promo_code_failed", err)
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.CreatePromoRedemption(r.Context(), database.CreatePromoRedemptionParams{
+			Code:       code,
+			UserID:     user.ID,
+			RedeemedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errPromoAlreadyRedeemed
+		}
+		// Claiming checks expiry and uses in the same statement that counts
+		// the use, so concurrent redemptions can't overshoot max_uses.
+		n, err = q.ClaimPromoCode(r.Context(), database.ClaimPromoCodeParams{
+			Code:      code,
+			ExpiresAt: sql.NullString{String: now, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errPromoUnavailable
+		}
+
+		if promo.Plan.Valid {
+			err := q.SetUserPlan(r.Context(), database.SetUserPlanParams{
+				UserID:    user.ID,
+				Plan:      promo.Plan.String,
+				UpdatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if promo.BonusNotes > 0 {
+			err := q.AddUserBonusNotes(r.Context(), database.AddUserBonusNotesParams{
+				UserID:     user.ID,
+				BonusNotes: promo.BonusNotes,
+				UpdatedAt:  now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(r.Context(), q, user.ID, "promo_code.redeemed", "user", user.ID, map[string]any{
+			"code":        code,
+			"plan":        promo.Plan.String,
+			"bonus_notes": promo.BonusNotes,
+		})
+	})
+	if errors.Is(err, errPromoAlreadyRedeemed) {
+		respondWithError(w, http.StatusConflict, "promo_code_already_redeemed", nil)
+		return
+	}
+	if errors.Is(err, errPromoUnavailable) {
+		respondWithError(w, http.StatusGone, "promo_code_unavailable", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "redeem_promo_code_failed", err)
+		return
+	}
+	cfg.plans.forget(user.ID)
+
+	cfg.respondWithPlan(w, r, user.ID)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const maxEmojiBytes = 32
+
+func validEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > maxEmojiBytes || !utf8.ValidString(emoji) {
+		return false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cfg *apiConfig) handlerReactionsSet(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Emoji string `json:"emoji"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if !validEmoji(params.Emoji) {
+		respondWithError(w, http.StatusBadRequest, "invalid_emoji", nil)
+		return
+	}
+
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	err = cfg.DB.UpsertReaction(r.Context(), database.UpsertReactionParams{
+		NoteID:    note.ID,
+		UserID:    user.ID,
+		Emoji:     params.Emoji,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "save_reaction_failed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerReactionsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	n, err := cfg.DB.DeleteReaction(r.Context(), database.DeleteReactionParams{
+		NoteID: note.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_reaction_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "reaction_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachReactions fills in per-emoji reaction counts on the user's notes.
+func (cfg *apiConfig) attachReactions(ctx context.Context, userID string, notes []Note) error {
+	counts, err := cfg.DB.GetReactionCountsForUserNotes(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	byNote := map[string]map[string]int64{}
+	for _, count := range counts {
+		if byNote[count.NoteID] == nil {
+			byNote[count.NoteID] = map[string]int64{}
+		}
+		byNote[count.NoteID][count.Emoji] = count.Count
+	}
+	for i := range notes {
+		notes[i].Reactions = byNote[notes[i].ID]
+	}
+	return nil
+}
@@ -1,7 +1,3 @@
 package main
 
-import "net/http"
-
-func handlerReadiness(w http.ResponseWriter, r *http.Request) {
-	respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok"})
-}
+var handlerReadiness = mustStaticJSON(map[string]string{"status": "ok"})
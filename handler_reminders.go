@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const reminderBatchSize = 100
+
+func (cfg *apiConfig) handlerNotesDueSet(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		DueAt time.Time `json:"due_at"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.DueAt.IsZero() {
+		respondWithError(w, http.StatusBadRequest, "due_at_is_required", nil)
+		return
+	}
+
+	cfg.setNoteDueAt(w, r, user, sql.NullString{
+		String: params.DueAt.UTC().Format(time.RFC3339),
+		Valid:  true,
+	})
+}
+
+func (cfg *apiConfig) handlerNotesDueClear(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.setNoteDueAt(w, r, user, sql.NullString{})
+}
+
+func (cfg *apiConfig) setNoteDueAt(w http.ResponseWriter, r *http.Request, user database.User, dueAt sql.NullString) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.SetNoteDueAt(r.Context(), database.SetNoteDueAtParams{
+			DueAt:     dueAt,
+			UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+			ID:        note.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(r.Context(), q, events.New(events.NoteUpdated, note.UserID, note.ID))
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_due_date_failed", err)
+		return
+	}
+
+	note, err = cfg.DB.GetNote(r.Context(), note.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+		return
+	}
+
+	noteResp, err := databaseNoteToNote(note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, noteResp)
+}
+
+// sendDueReminders fires a reminder for every note whose due date has passed
+// and marks it so it only fires once.
+func (cfg *apiConfig) sendDueReminders(ctx context.Context) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	notes, err := cfg.DB.GetDueReminders(ctx, database.GetDueRemindersParams{
+		DueAt: sql.NullString{String: now, Valid: true},
+		Limit: reminderBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		err := cfg.withTx(ctx, func(q *database.Queries) error {
+			err := q.MarkNoteReminded(ctx, database.MarkNoteRemindedParams{
+				RemindedAt: sql.NullString{String: now, Valid: true},
+				ID:         note.ID,
+			})
+			if err != nil {
+				return err
+			}
+			return enqueueEvent(ctx, q, events.New(events.ReminderDue, note.UserID, note.ID))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/cron"
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/go-chi/chi"
+)
+
+const scheduleBatchSize = 100
+
+// scheduleValues adds the values every scheduled note gets for free, such as
+// {{date}}, unless the schedule sets them itself.
+func scheduleValues(values map[string]string, now time.Time) map[string]string {
+	result := map[string]string{"date": now.UTC().Format(time.DateOnly)}
+	for k, v := range values {
+		result[k] = v
+	}
+	return result
+}
+
+func (cfg *apiConfig) handlerSchedulesCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		TemplateID string            `json:"template_id"`
+		Spec       string            `json:"spec"`
+		Values     map[string]string `json:"values"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Values == nil {
+		params.Values = map[string]string{}
+	}
+
+	schedule, err := cron.Parse(params.Spec)
+	if err != nil {
+		respondWithErrorDetail(w, http.StatusBadRequest, "invalid_schedule_spec", err.Error(), nil)
+		return
+	}
+	now := time.Now().UTC()
+	nextRunAt := schedule.Next(now)
+	if nextRunAt.IsZero() {
+		respondWithError(w, http.StatusBadRequest, "schedule_never_runs", nil)
+		return
+	}
+
+	template, err := cfg.DB.GetTemplate(r.Context(), params.TemplateID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && template.UserID != user.ID) {
+		respondWithError(w, http.StatusNotFound, "template_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_template_failed", err)
+		return
+	}
+	if _, err := renderTemplate(template.Body, scheduleValues(params.Values, now)); err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	values, err := json.Marshal(params.Values)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "encode_values_failed", err)
+		return
+	}
+
+	id := cfg.ids.New()
+	err = cfg.DB.CreateSchedule(r.Context(), database.CreateScheduleParams{
+		ID:             id,
+		CreatedAt:      now.Format(time.RFC3339),
+		UpdatedAt:      now.Format(time.RFC3339),
+		Spec:           params.Spec,
+		TemplateValues: string(values),
+		NextRunAt:      nextRunAt.Format(time.RFC3339),
+		TemplateID:     template.ID,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_schedule_failed", err)
+		return
+	}
+
+	dbSchedule, err := cfg.DB.GetSchedule(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_schedule_failed", err)
+		return
+	}
+
+	scheduleResp, err := databaseScheduleToSchedule(dbSchedule)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_schedule_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, scheduleResp)
+}
+
+func (cfg *apiConfig) handlerSchedulesGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	schedules, err := cfg.DB.GetSchedulesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_schedules_for_user_failed", err)
+		return
+	}
+
+	schedulesResp, err := databaseSchedulesToSchedules(schedules)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_schedules_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, schedulesResp)
+}
+
+func (cfg *apiConfig) handlerSchedulesDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	n, err := cfg.DB.DeleteScheduleForUser(r.Context(), database.DeleteScheduleForUserParams{
+		ID:     chi.URLParam(r, "scheduleID"),
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_schedule_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "schedule_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runSchedules creates a note for every schedule that is due. Runs missed
+// while the server was down are not made up; each schedule fires once and
+// moves on to its next future run.
+func (cfg *apiConfig) runSchedules(ctx context.Context) error {
+	now := time.Now().UTC()
+	schedules, err := cfg.DB.GetDueSchedules(ctx, database.GetDueSchedulesParams{
+		NextRunAt: now.Format(time.RFC3339),
+		Limit:     scheduleBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if err := cfg.runSchedule(ctx, schedule, now); err != nil {
+			log.Printf("Couldn't run schedule %s: %v", schedule.ID, err)
+		}
+
+		spec, err := cron.Parse(schedule.Spec)
+		if err != nil {
+			return err
+		}
+		err = cfg.DB.UpdateScheduleNextRun(ctx, database.UpdateScheduleNextRunParams{
+			NextRunAt: spec.Next(now).Format(time.RFC3339),
+			UpdatedAt: now.Format(time.RFC3339),
+			ID:        schedule.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) runSchedule(ctx context.Context, schedule database.Schedule, now time.Time) error {
+	// A suspended user's schedules keep moving to their next run, but don't
+	// create notes until the account is reactivated.
+	_, suspended, err := cfg.suspensionFor(ctx, schedule.UserID)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		return nil
+	}
+
+	template, err := cfg.DB.GetTemplate(ctx, schedule.TemplateID)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(schedule.TemplateValues), &values); err != nil {
+		return err
+	}
+
+	text, err := renderTemplate(template.Body, scheduleValues(values, now))
+	if err != nil {
+		return err
+	}
+
+	_, _, err = cfg.createNote(ctx, schedule.UserID, "", text)
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+	"github.com/bootdotdev/learn-cicd-starter/internal/slack"
+	"github.com/go-chi/chi"
+)
+
+const slackPostTimeout = 10 * time.Second
+
+func (cfg *apiConfig) handlerSlackIntegrationsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		WebhookURL string   `json:"webhook_url"`
+		EventTypes []string `json:"event_types"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if !slack.ValidWebhookURL(params.WebhookURL) {
+		respondWithError(w, http.StatusBadRequest, "invalid_slack_webhook_url", nil)
+		return
+	}
+	if len(params.EventTypes) == 0 {
+		respondWithError(w, http.StatusBadRequest, "event_types_is_required", nil)
+		return
+	}
+	for _, t := range params.EventTypes {
+		if !slices.Contains(events.Types, events.Type(t)) {
+			respondWithErrorDetail(w, http.StatusBadRequest, "unknown_event_type", t, nil)
+			return
+		}
+	}
+
+	id := cfg.ids.New()
+	err = cfg.DB.CreateSlackIntegration(r.Context(), database.CreateSlackIntegrationParams{
+		ID:         id,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
+		WebhookUrl: params.WebhookURL,
+		EventTypes: strings.Join(params.EventTypes, ","),
+		UserID:     user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_slack_integration_failed", err)
+		return
+	}
+
+	integration, err := cfg.DB.GetSlackIntegration(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_slack_integration_failed", err)
+		return
+	}
+
+	integrationResp, err := databaseSlackIntegrationToSlackIntegration(integration)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_slack_integration_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, integrationResp)
+}
+
+func (cfg *apiConfig) handlerSlackIntegrationsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	integrations, err := cfg.DB.GetSlackIntegrationsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_slack_integrations_for_user_failed", err)
+		return
+	}
+
+	integrationsResp, err := databaseSlackIntegrationsToSlackIntegrations(integrations)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_slack_integrations_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, integrationsResp)
+}
+
+func (cfg *apiConfig) handlerSlackIntegrationsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	n, err := cfg.DB.DeleteSlackIntegrationForUser(r.Context(), database.DeleteSlackIntegrationForUserParams{
+		ID:     chi.URLParam(r, "integrationID"),
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_slack_integration_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "slack_integration_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifySlack posts e to every Slack integration of the event's user that
+// subscribed to its type. It fails if any post does, so the outbox retries
+// the event; integrations that already got it then get it again.
+func (cfg *apiConfig) notifySlack(ctx context.Context, e events.Event) error {
+	integrations, err := cfg.DB.GetSlackIntegrationsForUser(ctx, e.UserID)
+	if err != nil {
+		return fmt.Errorf("couldn't get slack integrations for user %s: %w", e.UserID, err)
+	}
+
+	var webhookURLs []string
+	for _, integration := range integrations {
+		if slices.Contains(strings.Split(integration.EventTypes, ","), string(e.Type)) {
+			webhookURLs = append(webhookURLs, integration.WebhookUrl)
+		}
+	}
+	if len(webhookURLs) == 0 {
+		return nil
+	}
+
+	msg := cfg.slackMessage(ctx, e)
+	var errs []error
+	for _, webhookURL := range webhookURLs {
+		ctx, cancel := context.WithTimeout(ctx, slackPostTimeout)
+		if err := slack.Post(ctx, http.DefaultClient, webhookURL, msg); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't post %s event to slack: %w", e.Type, err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg *apiConfig) slackMessage(ctx context.Context, e events.Event) slack.Message {
+	var title string
+	switch e.Type {
+	case events.NoteCreated:
+		title = "New note"
+	case events.NoteUpdated:
+		title = "Note updated"
+	case events.ReminderDue:
+		title = "Reminder due"
+	case events.CommentCreated:
+		title = "New comment"
+	case events.UsageAlert:
+		title = "Usage alert"
+	default:
+		title = string(e.Type)
+	}
+
+	text := fmt.Sprintf("*%s*", title)
+	switch e.Type {
+	case events.CommentCreated:
+	case events.UsageAlert:
+		if alert, err := cfg.DB.GetUsageAlert(ctx, e.SubjectID); err == nil {
+			text += "\n>" + slack.Escape(usageAlertLabel(alert))
+		}
+	default:
+		if note, err := cfg.DB.GetNote(ctx, e.SubjectID); err == nil {
+			text += "\n>" + slack.Escape(noteLabel(note.Note))
+		}
+	}
+	return slack.Message{
+		Text:   title,
+		Blocks: []slack.Block{slack.Section(text)},
+	}
+}
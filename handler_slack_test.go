@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+func TestSlackMessageEscapesNoteText(t *testing.T) {
+	note := testNote
+	note.Note = "<!channel> <https://x.io|bank> & co"
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "name: GetNote :one") {
+			t.Errorf("unexpected query: %s", query)
+			return noteColumns, nil, nil
+		}
+		return noteColumns, [][]driver.Value{noteRow(note)}, nil
+	})
+
+	msg := cfg.slackMessage(context.Background(), events.New(events.NoteCreated, note.UserID, note.ID))
+	got := msg.Blocks[0].Text.Text
+	want := "*New note*\n>&lt;!channel&gt; &lt;https://x.io|bank&gt; &amp; co"
+	if got != want {
+		t.Errorf("message text = %q, want %q", got, want)
+	}
+}
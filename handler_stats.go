@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	statsCacheTTL    = time.Minute
+	statsTrendDays   = 30
+	statsTopAPIUsers = 10
+)
+
+type dayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+type apiConsumer struct {
+	UserID   string `json:"user_id"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type systemStats struct {
+	GeneratedAt     time.Time     `json:"generated_at"`
+	Users           int64         `json:"users"`
+	ActiveUsers     int64         `json:"active_users_today"`
+	Notes           int64         `json:"notes"`
+	NotesPerDay     []dayCount    `json:"notes_per_day"`
+	TopAPIConsumers []apiConsumer `json:"top_api_consumers"`
+	// DatabaseBytes is nil when the database doesn't report its size.
+	DatabaseBytes *int64 `json:"database_bytes"`
+}
+
+// statsCache holds the last computed stats. Callers wait on mu while stats
+// are computed, so a burst of dashboard refreshes runs the queries once.
+type statsCache struct {
+	mu    sync.Mutex
+	stats systemStats
+}
+
+func (cfg *apiConfig) handlerStatsGet(w http.ResponseWriter, r *http.Request) {
+	cfg.stats.mu.Lock()
+	defer cfg.stats.mu.Unlock()
+
+	now := time.Now().UTC()
+	if now.Sub(cfg.stats.stats.GeneratedAt) >= statsCacheTTL {
+		stats, err := cfg.computeStats(r.Context(), now)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "compute_stats_failed", err)
+			return
+		}
+		cfg.stats.stats = stats
+	}
+	respondWithJSON(w, http.StatusOK, cfg.stats.stats)
+}
+
+// computeStats gathers the dashboard's numbers. The notes trend covers the
+// last statsTrendDays days including today, with days without notes filled
+// in as zero; API consumers are ranked over the same period.
+func (cfg *apiConfig) computeStats(ctx context.Context, now time.Time) (systemStats, error) {
+	stats := systemStats{GeneratedAt: now}
+	var err error
+	if stats.Users, err = cfg.DB.CountUsers(ctx); err != nil {
+		return systemStats{}, err
+	}
+	if stats.Notes, err = cfg.DB.CountNotes(ctx); err != nil {
+		return systemStats{}, err
+	}
+	today := now.Format(usageDayLayout)
+	if stats.ActiveUsers, err = cfg.DB.CountActiveUsersOnDay(ctx, today); err != nil {
+		return systemStats{}, err
+	}
+
+	since := now.AddDate(0, 0, 1-statsTrendDays).Format(usageDayLayout)
+	perDay, err := cfg.DB.GetNotesCreatedPerDay(ctx, since)
+	if err != nil {
+		return systemStats{}, err
+	}
+	counts := make(map[string]int64, len(perDay))
+	for _, row := range perDay {
+		counts[row.Day] = row.Notes
+	}
+	stats.NotesPerDay = make([]dayCount, statsTrendDays)
+	for i := range stats.NotesPerDay {
+		day := now.AddDate(0, 0, i+1-statsTrendDays).Format(usageDayLayout)
+		stats.NotesPerDay[i] = dayCount{Day: day, Count: counts[day]}
+	}
+
+	consumers, err := cfg.DB.GetAPIUsageByUser(ctx, database.GetAPIUsageByUserParams{
+		Day:   since,
+		Day_2: today,
+		Limit: statsTopAPIUsers,
+	})
+	if err != nil {
+		return systemStats{}, err
+	}
+	stats.TopAPIConsumers = make([]apiConsumer, len(consumers))
+	for i, row := range consumers {
+		stats.TopAPIConsumers[i] = apiConsumer{UserID: row.UserID, Requests: row.Requests, Bytes: row.Bytes}
+	}
+
+	// Not every libsql server allows the pragmas behind this, so a failure
+	// leaves the size out rather than failing the whole response.
+	if size, err := cfg.DB.GetDatabaseSize(ctx); err != nil {
+		log.Printf("Couldn't get database size: %v", err)
+	} else {
+		stats.DatabaseBytes = &size
+	}
+	return stats, nil
+}
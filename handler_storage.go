@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const storageLargestItems = 10
+
+type storageCategory struct {
+	Count int64 `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+type storageItem struct {
+	ID        string    `json:"id"`
+	Category  string    `json:"category"`
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerUsersStorageGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		TotalBytes int64                      `json:"total_bytes"`
+		Categories map[string]storageCategory `json:"categories"`
+		Largest    []storageItem              `json:"largest"`
+	}
+
+	notesUsage, err := cfg.DB.GetNotesStorageForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_storage_usage_failed", err)
+		return
+	}
+
+	largestNotes, err := cfg.DB.GetLargestNotesForUser(r.Context(), database.GetLargestNotesForUserParams{
+		UserID: user.ID,
+		Limit:  storageLargestItems,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_largest_notes_failed", err)
+		return
+	}
+
+	largest := make([]storageItem, len(largestNotes))
+	for i, note := range largestNotes {
+		createdAt, err := parseDBTime(note.CreatedAt)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+			return
+		}
+		largest[i] = storageItem{
+			ID:        note.ID,
+			Category:  "notes",
+			Bytes:     note.Bytes,
+			CreatedAt: createdAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		TotalBytes: notesUsage.Bytes,
+		Categories: map[string]storageCategory{
+			"notes": {Count: notesUsage.NoteCount, Bytes: notesUsage.Bytes},
+		},
+		Largest: largest,
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const syncBatchSize = 500
+
+func encodeSyncToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+var errInvalidSyncToken = &apiError{code: "invalid_sync_token"}
+
+func decodeSyncToken(s string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errInvalidSyncToken
+	}
+	seq, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, errInvalidSyncToken
+	}
+	return seq, nil
+}
+
+// handlerSync returns the notes created, updated or deleted since the change
+// token in ?since. Without a token it returns every note, for an initial
+// sync. Clients pass next_token back until has_more is false.
+func (cfg *apiConfig) handlerSync(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		Notes     []Note   `json:"notes"`
+		Deleted   []string `json:"deleted"`
+		NextToken string   `json:"next_token"`
+		HasMore   bool     `json:"has_more"`
+	}
+
+	since := r.URL.Query().Get("since")
+	if since == "" {
+		// Read the position first: changes that land while the notes are
+		// read are sent again on the next sync, which is harmless.
+		seq, err := cfg.DB.GetLatestNoteChangeSeq(r.Context())
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "get_sync_position_failed", err)
+			return
+		}
+		notes, err := cfg.DB.GetNotesForUser(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+			return
+		}
+		notesResp, err := databasePostsToPosts(notes)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "convert_notes_failed", err)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, response{
+			Notes:     notesResp,
+			Deleted:   []string{},
+			NextToken: encodeSyncToken(seq),
+		})
+		return
+	}
+
+	seq, err := decodeSyncToken(since)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	changes, err := cfg.DB.GetNoteChangesForUserSince(r.Context(), database.GetNoteChangesForUserSinceParams{
+		UserID: user.ID,
+		Seq:    seq,
+		Limit:  syncBatchSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_changes_failed", err)
+		return
+	}
+
+	// Only the latest change to each note matters.
+	latest := map[string]database.NoteChange{}
+	var order []string
+	for _, change := range changes {
+		if _, ok := latest[change.NoteID]; !ok {
+			order = append(order, change.NoteID)
+		}
+		latest[change.NoteID] = change
+		seq = change.Seq
+	}
+
+	resp := response{
+		Notes:     []Note{},
+		Deleted:   []string{},
+		NextToken: encodeSyncToken(seq),
+		HasMore:   len(changes) == syncBatchSize,
+	}
+	for _, noteID := range order {
+		if latest[noteID].Deleted != 0 {
+			resp.Deleted = append(resp.Deleted, noteID)
+			continue
+		}
+		note, err := cfg.DB.GetNote(r.Context(), noteID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted after the last change in this batch; the tombstone
+			// arrives with the next one.
+			continue
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+			return
+		}
+		noteResp, err := databaseNoteToNote(note)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+			return
+		}
+		resp.Notes = append(resp.Notes, noteResp)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
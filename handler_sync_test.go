@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func FuzzDecodeSyncToken(f *testing.F) {
+	f.Add(encodeSyncToken(0))
+	f.Add(encodeSyncToken(42))
+	f.Add("")
+	f.Add("LTE")
+	f.Add("!!")
+	f.Fuzz(func(t *testing.T, s string) {
+		seq, err := decodeSyncToken(s)
+		if err != nil {
+			return
+		}
+		if seq < 0 {
+			t.Errorf("decodeSyncToken(%q) = %d, which is negative", s, seq)
+		}
+		if again, err := decodeSyncToken(encodeSyncToken(seq)); err != nil || again != seq {
+			t.Errorf("sync token %d didn't round-trip: %d, %v", seq, again, err)
+		}
+	})
+}
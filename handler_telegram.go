@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/telegram"
+)
+
+const (
+	telegramLinkCodeTTL      = 10 * time.Minute
+	telegramLinkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	telegramPollTimeout      = 30 * time.Second
+	telegramListLimit        = 5
+)
+
+const telegramHelp = `Send any message to save it as a note.
+/list - show your latest notes
+/link CODE - link this chat to your Notely account
+/unlink - unlink this chat`
+
+func (cfg *apiConfig) handlerTelegramLinkCodeCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		Code      string    `json:"code"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	code, err := generateTelegramLinkCode()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "generate_link_code_failed", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(telegramLinkCodeTTL)
+	err = cfg.DB.CreateTelegramLinkCode(r.Context(), database.CreateTelegramLinkCodeParams{
+		Code:      code,
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_link_code_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, response{
+		Code:      code,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func generateTelegramLinkCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = telegramLinkCodeAlphabet[int(b[i])%len(telegramLinkCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// runTelegramBot long-polls the bot for messages until ctx is cancelled.
+func (cfg *apiConfig) runTelegramBot(ctx context.Context, bot *telegram.Client) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := bot.GetUpdates(ctx, offset, telegramPollTimeout)
+		if err != nil {
+			log.Printf("telegram bot: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.Text == "" {
+				continue
+			}
+			reply := cfg.handleTelegramMessage(ctx, update.Message.Chat.ID, update.Message.Text)
+			if err := bot.SendMessage(ctx, update.Message.Chat.ID, reply); err != nil {
+				log.Printf("telegram bot: %v", err)
+			}
+		}
+
+		if err := cfg.DB.DeleteExpiredTelegramLinkCodes(ctx, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			log.Printf("telegram bot: couldn't delete expired link codes: %v", err)
+		}
+	}
+}
+
+// handleTelegramMessage processes one chat message and returns the reply.
+func (cfg *apiConfig) handleTelegramMessage(ctx context.Context, chatID int64, text string) string {
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	// In groups commands may be addressed as /list@SomeBot.
+	command, _, _ = strings.Cut(command, "@")
+
+	switch command {
+	case "/start", "/help":
+		return telegramHelp
+	case "/link":
+		return cfg.linkTelegramChat(ctx, chatID, strings.ToUpper(strings.TrimSpace(arg)))
+	}
+
+	link, err := cfg.DB.GetTelegramLink(ctx, chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "This chat isn't linked yet. Create a link code with POST /v1/integrations/telegram/link-code and send /link CODE."
+	}
+	if err != nil {
+		log.Printf("telegram bot: couldn't get link for chat %d: %v", chatID, err)
+		return "Something went wrong, please try again."
+	}
+
+	// Suspended users can still unlink, but not read or add notes.
+	if command != "/unlink" {
+		suspension, suspended, err := cfg.suspensionFor(ctx, link.UserID)
+		if err != nil {
+			log.Printf("telegram bot: couldn't check account status for chat %d: %v", chatID, err)
+			return "Something went wrong, please try again."
+		}
+		if suspended {
+			return "Your account is suspended: " + suspension.Reason
+		}
+	}
+
+	switch command {
+	case "/unlink":
+		if err := cfg.DB.DeleteTelegramLink(ctx, chatID); err != nil {
+			log.Printf("telegram bot: couldn't unlink chat %d: %v", chatID, err)
+			return "Couldn't unlink this chat."
+		}
+		return "Unlinked."
+	case "/list":
+		notes, err := cfg.DB.GetLatestNotesForUser(ctx, database.GetLatestNotesForUserParams{
+			UserID: link.UserID,
+			Limit:  telegramListLimit,
+		})
+		if err != nil {
+			log.Printf("telegram bot: couldn't list notes for chat %d: %v", chatID, err)
+			return "Couldn't get your notes."
+		}
+		if len(notes) == 0 {
+			return "You don't have any notes yet."
+		}
+		var b strings.Builder
+		for i, note := range notes {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, noteLabel(note.Note))
+		}
+		return b.String()
+	}
+
+	if strings.HasPrefix(command, "/") {
+		return telegramHelp
+	}
+
+	if _, _, err := cfg.createNote(ctx, link.UserID, "", strings.TrimSpace(text)); err != nil {
+		log.Printf("telegram bot: couldn't create note for chat %d: %v", chatID, err)
+		return "Couldn't save your note."
+	}
+	return "Saved."
+}
+
+func (cfg *apiConfig) linkTelegramChat(ctx context.Context, chatID int64, code string) string {
+	if code == "" {
+		return "Usage: /link CODE"
+	}
+
+	userID, err := cfg.DB.ClaimTelegramLinkCode(ctx, database.ClaimTelegramLinkCodeParams{
+		Code:      code,
+		ExpiresAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "That code is invalid or has expired."
+	}
+	if err != nil {
+		log.Printf("telegram bot: couldn't claim link code: %v", err)
+		return "Something went wrong, please try again."
+	}
+
+	err = cfg.DB.UpsertTelegramLink(ctx, database.UpsertTelegramLinkParams{
+		ChatID:    chatID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		UserID:    userID,
+	})
+	if err != nil {
+		log.Printf("telegram bot: couldn't link chat %d: %v", chatID, err)
+		return "Something went wrong, please try again."
+	}
+	return "Linked! Send any message to save it as a note."
+}
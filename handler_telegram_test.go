@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestTelegramRefusesSuspendedUsers(t *testing.T) {
+	var created bool
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "FROM telegram_links"):
+			return []string{"chat_id", "created_at", "user_id"}, [][]driver.Value{{int64(1), testUser.CreatedAt, testUser.ID}}, nil
+		case strings.Contains(query, "FROM user_suspensions"):
+			return []string{"user_id", "suspended_at", "reason"}, [][]driver.Value{{testUser.ID, testUser.CreatedAt, "spam"}}, nil
+		case strings.Contains(query, "INSERT INTO notes"):
+			created = true
+		}
+		return nil, nil, nil
+	})
+
+	for _, text := range []string{"/list", "Buy milk"} {
+		reply := cfg.handleTelegramMessage(context.Background(), 1, text)
+		if reply != "Your account is suspended: spam" {
+			t.Errorf("reply to %q = %q, want the suspension reason", text, reply)
+		}
+	}
+	if created {
+		t.Error("a note was created for a suspended user")
+	}
+}
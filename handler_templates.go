@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/go-chi/chi"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// renderTemplate replaces every {{placeholder}} in body with its value.
+// Every placeholder must have a value.
+func renderTemplate(body string, values map[string]string) (string, error) {
+	var missing []string
+	rendered := templatePlaceholder.ReplaceAllStringFunc(body, func(match string) string {
+		name := templatePlaceholder.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", &apiError{code: "missing_placeholder_values", detail: strings.Join(missing, ", ")}
+	}
+	return rendered, nil
+}
+
+func (cfg *apiConfig) handlerTemplatesCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name string `json:"name"`
+		Body string `json:"body"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "template_name_is_required", nil)
+		return
+	}
+
+	id := cfg.ids.New()
+	err = cfg.DB.CreateTemplate(r.Context(), database.CreateTemplateParams{
+		ID:        id,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		Name:      params.Name,
+		Body:      params.Body,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_template_failed", err)
+		return
+	}
+
+	template, err := cfg.DB.GetTemplate(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_template_failed", err)
+		return
+	}
+
+	templateResp, err := databaseTemplateToTemplate(template)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_template_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, templateResp)
+}
+
+func (cfg *apiConfig) handlerTemplatesGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	templates, err := cfg.DB.GetTemplatesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_templates_for_user_failed", err)
+		return
+	}
+
+	templatesResp, err := databaseTemplatesToTemplates(templates)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_templates_failed", err)
+		return
+	}
+
+	respondWithFields(w, r, http.StatusOK, templatesResp)
+}
+
+func (cfg *apiConfig) handlerTemplatesInstantiate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Values map[string]string `json:"values"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+
+	template, err := cfg.DB.GetTemplate(r.Context(), chi.URLParam(r, "templateID"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && template.UserID != user.ID) {
+		respondWithError(w, http.StatusNotFound, "template_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_template_failed", err)
+		return
+	}
+
+	text, err := renderTemplate(template.Body, params.Values)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cfg.respondWithNewNote(w, r, user, "", text)
+}
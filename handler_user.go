@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/bootdotdev/learn-cicd-starter/internal/database"
-	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request) {
@@ -20,37 +19,37 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 	params := parameters{}
 	err := decoder.Decode(&params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
 		return
 	}
 
 	apiKey, err := generateRandomSHA256Hash()
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't gen apikey", err)
+		respondWithError(w, http.StatusInternalServerError, "generate_api_key_failed", err)
 		return
 	}
 
 	err = cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
-		ID:        uuid.New().String(),
+		ID:        cfg.ids.New(),
 		CreatedAt: time.Now().UTC().Format(time.RFC3339),
 		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
 		Name:      params.Name,
 		ApiKey:    apiKey,
 	})
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create user", err)
+		respondWithError(w, http.StatusInternalServerError, "create_user_failed", err)
 		return
 	}
 
 	user, err := cfg.DB.GetUser(r.Context(), apiKey)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		respondWithError(w, http.StatusInternalServerError, "get_user_failed", err)
 		return
 	}
 
 	userResp, err := databaseUserToUser(user)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't convert user", err)
+		respondWithError(w, http.StatusInternalServerError, "convert_user_failed", err)
 		return
 	}
 	respondWithJSON(w, http.StatusCreated, userResp)
@@ -71,9 +70,9 @@ func (cfg *apiConfig) handlerUsersGet(w http.ResponseWriter, r *http.Request, us
 
 	userResp, err := databaseUserToUser(user)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't convert user", err)
+		respondWithError(w, http.StatusInternalServerError, "convert_user_failed", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, userResp)
+	respondWithFields(w, r, http.StatusOK, userResp)
 }
@@ -0,0 +1,24 @@
+package main
+
+import "runtime"
+
+// Set at build time with -ldflags "-X main.version=...", see scripts/buildprod.sh.
+var (
+	version   = "dev"
+	gitSHA    = "unknown"
+	buildDate = "unknown"
+)
+
+// The build info can't change while the process runs, so the response is
+// encoded once. -ldflags -X values are in place before package initialization.
+var handlerVersion = mustStaticJSON(struct {
+	Version   string `json:"version"`
+	GitSHA    string `json:"git_sha"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}{
+	Version:   version,
+	GitSHA:    gitSHA,
+	BuildDate: buildDate,
+	GoVersion: runtime.Version(),
+})
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	// impersonationTokenPrefix tells impersonation tokens apart from API
+	// keys, which are plain hex.
+	impersonationTokenPrefix = "imp_"
+
+	defaultImpersonationTTL = 15 * time.Minute
+	maxImpersonationTTL     = time.Hour
+)
+
+// handlerImpersonationCreate mints a short-lived token that authenticates as
+// userID, for support to reproduce what the user sees. Tokens are read-only
+// unless allow_writes is set. Minting and every request made with the token
+// are recorded in the audit log.
+func (cfg *apiConfig) handlerImpersonationCreate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason      string `json:"reason"`
+		TTLMinutes  int    `json:"ttl_minutes"`
+		AllowWrites bool   `json:"allow_writes"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "reason_is_required", nil)
+		return
+	}
+	ttl := defaultImpersonationTTL
+	if params.TTLMinutes != 0 {
+		ttl = time.Duration(params.TTLMinutes) * time.Minute
+		if ttl < 0 || ttl > maxImpersonationTTL {
+			respondWithError(w, http.StatusBadRequest, "invalid_ttl_minutes", nil)
+			return
+		}
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), chi.URLParam(r, "userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_user_failed", err)
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "generate_token_failed", err)
+		return
+	}
+	token := impersonationTokenPrefix + hex.EncodeToString(b)
+	tokenHash := hashSessionToken(token)
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.CreateImpersonationToken(r.Context(), database.CreateImpersonationTokenParams{
+			TokenHash:   tokenHash,
+			CreatedAt:   now.Format(time.RFC3339),
+			ExpiresAt:   expiresAt.Format(time.RFC3339),
+			UserID:      user.ID,
+			Reason:      params.Reason,
+			AllowWrites: params.AllowWrites,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(r.Context(), q, auditActorAdmin, "impersonation.started", "user", user.ID, map[string]any{
+			"token":        impersonationTokenRef(tokenHash),
+			"reason":       params.Reason,
+			"expires_at":   expiresAt.Format(time.RFC3339),
+			"allow_writes": params.AllowWrites,
+		})
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_impersonation_token_failed", err)
+		return
+	}
+
+	type response struct {
+		Token       string    `json:"token"`
+		UserID      string    `json:"user_id"`
+		ExpiresAt   time.Time `json:"expires_at"`
+		AllowWrites bool      `json:"allow_writes"`
+	}
+	respondWithJSON(w, http.StatusCreated, response{
+		Token:       token,
+		UserID:      user.ID,
+		ExpiresAt:   expiresAt,
+		AllowWrites: params.AllowWrites,
+	})
+}
+
+// impersonationTokenRef identifies a token in the audit log without storing
+// anything that could be used to authenticate.
+func impersonationTokenRef(tokenHash string) string {
+	return tokenHash[:12]
+}
+
+// serveImpersonated is middlewareAuth for impersonation tokens. Impersonated
+// requests are flagged with an X-Impersonated header, audited with their
+// outcome, and count towards neither the user's rate limit nor their usage.
+func (cfg *apiConfig) serveImpersonated(w http.ResponseWriter, r *http.Request, token string, handler authedHandler) {
+	tokenHash := hashSessionToken(token)
+	imp, err := cfg.DB.GetImpersonationToken(r.Context(), database.GetImpersonationTokenParams{
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusUnauthorized, "invalid_impersonation_token", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_impersonation_token_failed", err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if !imp.AllowWrites {
+			respondWithError(w, http.StatusForbidden, "impersonation_token_read_only", nil)
+			return
+		}
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), imp.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "get_user_failed", err)
+		return
+	}
+	setRequestUser(r, user.ID)
+	w.Header().Set("X-Impersonated", "true")
+
+	rec := &statusRecorder{ResponseWriter: w}
+	handler(rec, r, user)
+
+	// Audit even if the client has gone away, since the request still ran.
+	ctx := context.WithoutCancel(r.Context())
+	err = recordAudit(ctx, cfg.DB, auditActorAdmin, "impersonation.request", "user", user.ID, map[string]any{
+		"token":  impersonationTokenRef(tokenHash),
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"status": rec.status,
+	})
+	if err != nil {
+		log.Printf("Couldn't audit impersonated request: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package analytics records anonymized usage events. Events carry only the
+// route pattern, method, a latency bucket and the status class: no user IDs,
+// IP addresses, note content or concrete URLs.
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/statsd"
+)
+
+type Event struct {
+	Endpoint string `json:"endpoint"`
+	Method   string `json:"method"`
+	Latency  string `json:"latency"`
+	Result   string `json:"result"`
+}
+
+// NewEvent builds an event for a finished request. endpoint should be the
+// route pattern (e.g. /v1/notes/{noteID}), never the raw path.
+func NewEvent(endpoint, method string, elapsed time.Duration, status int) Event {
+	if endpoint == "" {
+		endpoint = "unmatched"
+	}
+	return Event{
+		Endpoint: endpoint,
+		Method:   method,
+		Latency:  LatencyBucket(elapsed),
+		Result:   strconv.Itoa(status/100) + "xx",
+	}
+}
+
+var latencyBuckets = []struct {
+	max   time.Duration
+	label string
+}{
+	{10 * time.Millisecond, "<10ms"},
+	{50 * time.Millisecond, "<50ms"},
+	{100 * time.Millisecond, "<100ms"},
+	{500 * time.Millisecond, "<500ms"},
+	{time.Second, "<1s"},
+}
+
+func LatencyBucket(d time.Duration) string {
+	for _, b := range latencyBuckets {
+		if d < b.max {
+			return b.label
+		}
+	}
+	return ">=1s"
+}
+
+// Sink receives events. Record must not block the request.
+type Sink interface {
+	Record(Event)
+}
+
+// New returns the sink of the given kind: "log", "statsd" (target is
+// host:port) or "http" (target is a URL that receives JSON batches).
+func New(kind, target string) (Sink, error) {
+	switch kind {
+	case "log":
+		return logSink{}, nil
+	case "statsd":
+		client, err := statsd.New(target, "notely.analytics.")
+		if err != nil {
+			return nil, err
+		}
+		return statsdSink{client: client}, nil
+	case "http":
+		if target == "" {
+			return nil, fmt.Errorf("http analytics needs a URL")
+		}
+		return newHTTPSink(target), nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q", kind)
+	}
+}
+
+type logSink struct{}
+
+func (logSink) Record(e Event) {
+	log.Printf("analytics: %s %s %s %s", e.Method, e.Endpoint, e.Result, e.Latency)
+}
+
+type statsdSink struct {
+	client *statsd.Client
+}
+
+func (s statsdSink) Record(e Event) {
+	s.client.Count("requests", 1,
+		"endpoint:"+e.Endpoint,
+		"method:"+e.Method,
+		"latency:"+e.Latency,
+		"result:"+e.Result,
+	)
+}
+
+const (
+	httpBatchSize     = 100
+	httpFlushInterval = 10 * time.Second
+)
+
+// httpSink posts events in JSON batches from a background goroutine. Events
+// are dropped when the buffer is full rather than slowing requests down.
+type httpSink struct {
+	url    string
+	client *http.Client
+	events chan Event
+}
+
+func newHTTPSink(url string) *httpSink {
+	s := &httpSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		events: make(chan Event, 10*httpBatchSize),
+	}
+	go s.run()
+	return s
+}
+
+func (s *httpSink) Record(e Event) {
+	select {
+	case s.events <- e:
+	default:
+	}
+}
+
+func (s *httpSink) run() {
+	ticker := time.NewTicker(httpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, httpBatchSize)
+	for {
+		select {
+		case e := <-s.events:
+			batch = append(batch, e)
+			if len(batch) < httpBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := s.post(batch); err != nil {
+			log.Printf("analytics: couldn't send %d events: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+}
+
+func (s *httpSink) post(batch []Event) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("analytics endpoint responded %d", resp.StatusCode)
+	}
+	return nil
+}
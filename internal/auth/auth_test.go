@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", header: "ApiKey abc123", want: "abc123"},
+		{name: "missing", header: "", wantErr: true},
+		{name: "wrong scheme", header: "Bearer abc123", wantErr: true},
+		{name: "no key", header: "ApiKey", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			if tt.header != "" {
+				headers.Set("Authorization", tt.header)
+			}
+			got, err := GetAPIKey(headers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAPIKey(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetAPIKey(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzGetAPIKey(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"ApiKey abc123",
+		"ApiKey ",
+		"ApiKey",
+		"Bearer abc123",
+		"ApiKey  two  spaces",
+		"ApiKey \x00\xff",
+		strings.Repeat("ApiKey ", 100),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, header string) {
+		headers := http.Header{}
+		headers.Set("Authorization", header)
+		key, err := GetAPIKey(headers)
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(header, "ApiKey ") {
+			t.Errorf("GetAPIKey(%q) accepted a header without the ApiKey scheme", header)
+		}
+		if strings.Contains(key, " ") {
+			t.Errorf("GetAPIKey(%q) = %q, which contains a space", header, key)
+		}
+	})
+}
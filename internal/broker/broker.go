@@ -0,0 +1,23 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Publisher sends messages to an external message broker.
+type Publisher interface {
+	Publish(ctx context.Context, subject string, payload []byte) error
+	Close() error
+}
+
+// New returns a publisher for the named broker kind. NATS is the only one
+// supported.
+func New(kind, url string) (Publisher, error) {
+	switch kind {
+	case "nats":
+		return NewNATS(url)
+	default:
+		return nil, fmt.Errorf("unknown broker %q", kind)
+	}
+}
@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const natsDialTimeout = 5 * time.Second
+
+// natsConnectOptions is the JSON sent with CONNECT.
+type natsConnectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+// NATS is a minimal publish-only client for the NATS text protocol. It
+// connects lazily and reconnects on the next Publish after a failure.
+type NATS struct {
+	addr string
+	user *url.Userinfo
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewNATS(rawURL string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("expected a nats:// url, got %q", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATS{addr: addr, user: u.User}, nil
+}
+
+func (n *NATS) Publish(ctx context.Context, subject string, payload []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid subject %q", subject)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		n.conn.SetWriteDeadline(deadline)
+	} else {
+		n.conn.SetWriteDeadline(time.Time{})
+	}
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\n", subject, len(payload), payload)
+	if _, err := n.conn.Write([]byte(msg)); err != nil {
+		n.conn.Close()
+		n.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn = nil
+	return err
+}
+
+// connect dials the server and performs the CONNECT handshake. The caller
+// must hold n.mu.
+func (n *NATS) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(natsDialTimeout))
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting from nats server: %q", strings.TrimSpace(info))
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	options := natsConnectOptions{Name: "notely"}
+	if n.user != nil {
+		options.User = n.user.Username()
+		options.Pass, _ = n.user.Password()
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := conn.Write([]byte("CONNECT " + string(connect) + "\r\n")); err != nil {
+		conn.Close()
+		return err
+	}
+
+	n.conn = conn
+	go n.readLoop(conn, reader)
+	return nil
+}
+
+// readLoop answers server PINGs so the server keeps the connection open, and
+// drops the connection on errors.
+func (n *NATS) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err == nil && strings.HasPrefix(line, "-ERR") {
+			err = errors.New(strings.TrimSpace(line))
+		}
+		if err == nil && strings.HasPrefix(line, "PING") {
+			n.mu.Lock()
+			_, err = conn.Write([]byte("PONG\r\n"))
+			n.mu.Unlock()
+		}
+		if err != nil {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.conn = nil
+			}
+			n.mu.Unlock()
+			conn.Close()
+			return
+		}
+	}
+}
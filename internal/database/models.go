@@ -4,14 +4,209 @@
 
 package database
 
-import ()
+import (
+	"database/sql"
+)
+
+type ApiUsage struct {
+	UserID   string
+	Day      string
+	Requests int64
+	Bytes    int64
+}
+
+type AuditLog struct {
+	ID          int64
+	CreatedAt   string
+	Actor       string
+	Action      string
+	SubjectType string
+	SubjectID   string
+	Details     string
+}
+
+type Comment struct {
+	ID        string
+	CreatedAt string
+	UpdatedAt string
+	Body      string
+	NoteID    string
+	UserID    string
+}
+
+type ErasureRequest struct {
+	UserID            string
+	CreatedAt         string
+	ConfirmationToken string
+	ConfirmedAt       sql.NullString
+	EraseAfter        sql.NullString
+}
+
+type Export struct {
+	ID        string
+	CreatedAt string
+	UpdatedAt string
+	Status    string
+	Error     sql.NullString
+	Content   sql.NullString
+	UserID    string
+	Kind      string
+}
+
+type ImpersonationToken struct {
+	TokenHash   string
+	CreatedAt   string
+	ExpiresAt   string
+	UserID      string
+	Reason      string
+	AllowWrites bool
+}
+
+type LegalHold struct {
+	SubjectType string
+	SubjectID   string
+	CreatedAt   string
+	Reason      string
+}
+
+type ModerationFlag struct {
+	NoteID    string
+	CreatedAt string
+	Reason    string
+}
 
 type Note struct {
+	ID              string
+	CreatedAt       string
+	UpdatedAt       string
+	Note            string
+	UserID          string
+	DueAt           sql.NullString
+	RemindedAt      sql.NullString
+	Slug            sql.NullString
+	Version         int64
+	Language        sql.NullString
+	Title           sql.NullString
+	TitleCustom     bool
+	Properties      string
+	Latitude        sql.NullFloat64
+	Longitude       sql.NullFloat64
+	PropertyProject sql.NullString
+}
+
+type NoteChange struct {
+	Seq       int64
+	ChangedAt string
+	NoteID    string
+	UserID    string
+	Deleted   int64
+}
+
+type OutboxEvent struct {
+	ID           int64
+	CreatedAt    string
+	Payload      string
+	SentAt       sql.NullString
+	ClaimedUntil sql.NullString
+	Attempts     int64
+}
+
+type Plan struct {
+	Name              string
+	NotesLimit        int64
+	RequestsPerMinute int64
+}
+
+type PromoCode struct {
+	Code       string
+	Plan       sql.NullString
+	BonusNotes int64
+	MaxUses    int64
+	Uses       int64
+	ExpiresAt  sql.NullString
+	CreatedAt  string
+}
+
+type PromoRedemption struct {
+	Code       string
+	UserID     string
+	RedeemedAt string
+}
+
+type Reaction struct {
+	NoteID    string
+	UserID    string
+	Emoji     string
+	CreatedAt string
+}
+
+type Schedule struct {
+	ID             string
+	CreatedAt      string
+	UpdatedAt      string
+	Spec           string
+	TemplateValues string
+	NextRunAt      string
+	TemplateID     string
+	UserID         string
+}
+
+type Session struct {
+	TokenHash string
+	CreatedAt string
+	ExpiresAt string
+	UserID    string
+}
+
+type Setting struct {
+	Key       string
+	Value     string
+	UpdatedAt string
+}
+
+type SlackIntegration struct {
+	ID         string
+	CreatedAt  string
+	UpdatedAt  string
+	WebhookUrl string
+	EventTypes string
+	UserID     string
+}
+
+type TelegramLink struct {
+	ChatID    int64
+	CreatedAt string
+	UserID    string
+}
+
+type TelegramLinkCode struct {
+	Code      string
+	CreatedAt string
+	ExpiresAt string
+	UserID    string
+}
+
+type Template struct {
 	ID        string
 	CreatedAt string
 	UpdatedAt string
-	Note      string
+	Name      string
+	Body      string
+	UserID    string
+}
+
+type ThrottleExemption struct {
 	UserID    string
+	CreatedAt string
+}
+
+type UsageAlert struct {
+	ID          string
+	CreatedAt   string
+	UserID      string
+	Metric      string
+	Threshold   int64
+	TriggeredAt sql.NullString
 }
 
 type User struct {
@@ -21,3 +216,17 @@ type User struct {
 	Name      string
 	ApiKey    string
 }
+
+type UserPlan struct {
+	UserID           string
+	Plan             string
+	StripeCustomerID sql.NullString
+	UpdatedAt        string
+	BonusNotes       int64
+}
+
+type UserSuspension struct {
+	UserID      string
+	SuspendedAt string
+	Reason      string
+}
@@ -7,19 +7,41 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countNotesForUserSince = `-- name: CountNotesForUserSince :one
+
+SELECT COUNT(*) FROM notes WHERE user_id = ? AND created_at >= ?
+`
+
+type CountNotesForUserSinceParams struct {
+	UserID    string
+	CreatedAt string
+}
+
+func (q *Queries) CountNotesForUserSince(ctx context.Context, arg CountNotesForUserSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNotesForUserSince, arg.UserID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createNote = `-- name: CreateNote :exec
-INSERT INTO notes (id, created_at, updated_at, note, user_id)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO notes (id, created_at, updated_at, note, user_id, slug, language, title, title_custom)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateNoteParams struct {
-	ID        string
-	CreatedAt string
-	UpdatedAt string
-	Note      string
-	UserID    string
+	ID          string
+	CreatedAt   string
+	UpdatedAt   string
+	Note        string
+	UserID      string
+	Slug        sql.NullString
+	Language    sql.NullString
+	Title       sql.NullString
+	TitleCustom bool
 }
 
 func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) error {
@@ -29,13 +51,263 @@ func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) error {
 		arg.UpdatedAt,
 		arg.Note,
 		arg.UserID,
+		arg.Slug,
+		arg.Language,
+		arg.Title,
+		arg.TitleCustom,
 	)
 	return err
 }
 
+const deleteNotesForUserBefore = `-- name: DeleteNotesForUserBefore :execrows
+
+DELETE FROM notes WHERE id IN (
+    SELECT id FROM notes WHERE user_id = ? AND created_at < ?
+    AND id NOT IN (SELECT subject_id FROM legal_holds WHERE subject_type = 'note')
+    LIMIT ?
+)
+`
+
+type DeleteNotesForUserBeforeParams struct {
+	UserID    string
+	CreatedAt string
+	Limit     int64
+}
+
+func (q *Queries) DeleteNotesForUserBefore(ctx context.Context, arg DeleteNotesForUserBeforeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteNotesForUserBefore, arg.UserID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const filterNotesForUser = `-- name: FilterNotesForUser :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes
+WHERE user_id = ?1
+AND (?2 IS NULL OR id IN (SELECT value FROM json_each(?2)))
+AND (?3 IS NULL OR language = ?3)
+AND (?4 IS NULL OR title LIKE ?4 ESCAPE '\')
+AND (?5 IS NULL OR (due_at IS NOT NULL AND due_at < ?5))
+AND (?6 IS NULL OR id IN (
+    SELECT id FROM notes WHERE user_id = ?1 AND property_project = ?6
+))
+AND NOT EXISTS (
+    SELECT 1 FROM json_each(CAST(?7 AS TEXT)) AS want
+    WHERE json_extract(notes.properties, '$."' || want.key || '"') IS NOT want.value
+)
+ORDER BY CASE WHEN ?5 IS NULL THEN NULL ELSE due_at END, created_at DESC, id DESC
+`
+
+type FilterNotesForUserParams struct {
+	UserID     string
+	Ids        sql.NullString
+	Language   sql.NullString
+	Title      sql.NullString
+	DueBefore  sql.NullString
+	Project    sql.NullString
+	Properties string
+}
+
+func (q *Queries) FilterNotesForUser(ctx context.Context, arg FilterNotesForUserParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, filterNotesForUser,
+		arg.UserID,
+		arg.Ids,
+		arg.Language,
+		arg.Title,
+		arg.DueBefore,
+		arg.Project,
+		arg.Properties,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueReminders = `-- name: GetDueReminders :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes
+WHERE due_at IS NOT NULL AND due_at <= ? AND reminded_at IS NULL
+ORDER BY due_at
+LIMIT ?
+`
+
+type GetDueRemindersParams struct {
+	DueAt sql.NullString
+	Limit int64
+}
+
+func (q *Queries) GetDueReminders(ctx context.Context, arg GetDueRemindersParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getDueReminders, arg.DueAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLargestNotesForUser = `-- name: GetLargestNotesForUser :many
+
+SELECT id, created_at, CAST(LENGTH(CAST(note AS BLOB)) AS INTEGER) AS bytes
+FROM notes WHERE user_id = ?
+ORDER BY bytes DESC
+LIMIT ?
+`
+
+type GetLargestNotesForUserParams struct {
+	UserID string
+	Limit  int64
+}
+
+type GetLargestNotesForUserRow struct {
+	ID        string
+	CreatedAt string
+	Bytes     int64
+}
+
+func (q *Queries) GetLargestNotesForUser(ctx context.Context, arg GetLargestNotesForUserParams) ([]GetLargestNotesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLargestNotesForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLargestNotesForUserRow
+	for rows.Next() {
+		var i GetLargestNotesForUserRow
+		if err := rows.Scan(&i.ID, &i.CreatedAt, &i.Bytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestNotesForUser = `-- name: GetLatestNotesForUser :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes WHERE user_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?
+`
+
+type GetLatestNotesForUserParams struct {
+	UserID string
+	Limit  int64
+}
+
+func (q *Queries) GetLatestNotesForUser(ctx context.Context, arg GetLatestNotesForUserParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestNotesForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNote = `-- name: GetNote :one
 
-SELECT id, created_at, updated_at, note, user_id FROM notes WHERE id = ?
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes WHERE id = ?
 `
 
 func (q *Queries) GetNote(ctx context.Context, id string) (Note, error) {
@@ -47,13 +319,53 @@ func (q *Queries) GetNote(ctx context.Context, id string) (Note, error) {
 		&i.UpdatedAt,
 		&i.Note,
 		&i.UserID,
+		&i.DueAt,
+		&i.RemindedAt,
+		&i.Slug,
+		&i.Version,
+		&i.Language,
+		&i.Title,
+		&i.TitleCustom,
+		&i.Properties,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PropertyProject,
+	)
+	return i, err
+}
+
+const getNoteBySlug = `-- name: GetNoteBySlug :one
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes WHERE slug = ?
+`
+
+func (q *Queries) GetNoteBySlug(ctx context.Context, slug sql.NullString) (Note, error) {
+	row := q.db.QueryRowContext(ctx, getNoteBySlug, slug)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Note,
+		&i.UserID,
+		&i.DueAt,
+		&i.RemindedAt,
+		&i.Slug,
+		&i.Version,
+		&i.Language,
+		&i.Title,
+		&i.TitleCustom,
+		&i.Properties,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PropertyProject,
 	)
 	return i, err
 }
 
 const getNotesForUser = `-- name: GetNotesForUser :many
 
-SELECT id, created_at, updated_at, note, user_id FROM notes WHERE user_id = ?
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes WHERE user_id = ?
 `
 
 func (q *Queries) GetNotesForUser(ctx context.Context, userID string) ([]Note, error) {
@@ -71,6 +383,79 @@ func (q *Queries) GetNotesForUser(ctx context.Context, userID string) ([]Note, e
 			&i.UpdatedAt,
 			&i.Note,
 			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotesForUserAfter = `-- name: GetNotesForUserAfter :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes
+WHERE user_id = ? AND (created_at > ? OR (created_at = ? AND id > ?))
+ORDER BY created_at, id
+LIMIT ?
+`
+
+type GetNotesForUserAfterParams struct {
+	UserID      string
+	CreatedAt   string
+	CreatedAt_2 string
+	ID          string
+	Limit       int64
+}
+
+func (q *Queries) GetNotesForUserAfter(ctx context.Context, arg GetNotesForUserAfterParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesForUserAfter,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.CreatedAt_2,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
 		); err != nil {
 			return nil, err
 		}
@@ -84,3 +469,375 @@ func (q *Queries) GetNotesForUser(ctx context.Context, userID string) ([]Note, e
 	}
 	return items, nil
 }
+
+const getNotesForUserInBox = `-- name: GetNotesForUserInBox :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes
+WHERE user_id = ?
+AND latitude BETWEEN CAST(? AS REAL) AND CAST(? AS REAL)
+AND (longitude BETWEEN CAST(? AS REAL) AND CAST(? AS REAL)
+    OR longitude BETWEEN CAST(? AS REAL) AND CAST(? AS REAL))
+`
+
+type GetNotesForUserInBoxParams struct {
+	UserID        string
+	MinLat        float64
+	MaxLat        float64
+	MinLng        float64
+	MaxLng        float64
+	WrappedMinLng float64
+	WrappedMaxLng float64
+}
+
+func (q *Queries) GetNotesForUserInBox(ctx context.Context, arg GetNotesForUserInBoxParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesForUserInBox,
+		arg.UserID,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.WrappedMinLng,
+		arg.WrappedMaxLng,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotesStorageForUser = `-- name: GetNotesStorageForUser :one
+
+SELECT COUNT(*) AS note_count, CAST(COALESCE(SUM(LENGTH(CAST(note AS BLOB))), 0) AS INTEGER) AS bytes
+FROM notes WHERE user_id = ?
+`
+
+type GetNotesStorageForUserRow struct {
+	NoteCount int64
+	Bytes     int64
+}
+
+func (q *Queries) GetNotesStorageForUser(ctx context.Context, userID string) (GetNotesStorageForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getNotesStorageForUser, userID)
+	var i GetNotesStorageForUserRow
+	err := row.Scan(&i.NoteCount, &i.Bytes)
+	return i, err
+}
+
+const getNotesWithDueAtForUser = `-- name: GetNotesWithDueAtForUser :many
+
+SELECT id, created_at, updated_at, note, user_id, due_at, reminded_at, slug, version, language, title, title_custom, properties, latitude, longitude, property_project FROM notes
+WHERE user_id = ? AND due_at IS NOT NULL
+ORDER BY due_at
+`
+
+func (q *Queries) GetNotesWithDueAtForUser(ctx context.Context, userID string) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesWithDueAtForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Note,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindedAt,
+			&i.Slug,
+			&i.Version,
+			&i.Language,
+			&i.Title,
+			&i.TitleCustom,
+			&i.Properties,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PropertyProject,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotesWithoutLanguage = `-- name: GetNotesWithoutLanguage :many
+
+SELECT id, note FROM notes WHERE language IS NULL LIMIT ?
+`
+
+type GetNotesWithoutLanguageRow struct {
+	ID   string
+	Note string
+}
+
+func (q *Queries) GetNotesWithoutLanguage(ctx context.Context, limit int64) ([]GetNotesWithoutLanguageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesWithoutLanguage, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNotesWithoutLanguageRow
+	for rows.Next() {
+		var i GetNotesWithoutLanguageRow
+		if err := rows.Scan(&i.ID, &i.Note); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotesWithoutSlug = `-- name: GetNotesWithoutSlug :many
+
+SELECT id FROM notes WHERE slug IS NULL LIMIT ?
+`
+
+func (q *Queries) GetNotesWithoutSlug(ctx context.Context, limit int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesWithoutSlug, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotesWithoutTitle = `-- name: GetNotesWithoutTitle :many
+
+SELECT id, note FROM notes WHERE title IS NULL LIMIT ?
+`
+
+type GetNotesWithoutTitleRow struct {
+	ID   string
+	Note string
+}
+
+func (q *Queries) GetNotesWithoutTitle(ctx context.Context, limit int64) ([]GetNotesWithoutTitleRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesWithoutTitle, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNotesWithoutTitleRow
+	for rows.Next() {
+		var i GetNotesWithoutTitleRow
+		if err := rows.Scan(&i.ID, &i.Note); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markNoteReminded = `-- name: MarkNoteReminded :exec
+
+UPDATE notes SET reminded_at = ? WHERE id = ?
+`
+
+type MarkNoteRemindedParams struct {
+	RemindedAt sql.NullString
+	ID         string
+}
+
+func (q *Queries) MarkNoteReminded(ctx context.Context, arg MarkNoteRemindedParams) error {
+	_, err := q.db.ExecContext(ctx, markNoteReminded, arg.RemindedAt, arg.ID)
+	return err
+}
+
+const setNoteDueAt = `-- name: SetNoteDueAt :exec
+
+UPDATE notes SET due_at = ?, reminded_at = NULL, updated_at = ?, version = version + 1 WHERE id = ?
+`
+
+type SetNoteDueAtParams struct {
+	DueAt     sql.NullString
+	UpdatedAt string
+	ID        string
+}
+
+func (q *Queries) SetNoteDueAt(ctx context.Context, arg SetNoteDueAtParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteDueAt, arg.DueAt, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setNoteLanguage = `-- name: SetNoteLanguage :exec
+
+UPDATE notes SET language = ? WHERE id = ?
+`
+
+type SetNoteLanguageParams struct {
+	Language sql.NullString
+	ID       string
+}
+
+func (q *Queries) SetNoteLanguage(ctx context.Context, arg SetNoteLanguageParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteLanguage, arg.Language, arg.ID)
+	return err
+}
+
+const setNoteLocation = `-- name: SetNoteLocation :exec
+
+UPDATE notes SET latitude = ?, longitude = ?, updated_at = ?, version = version + 1 WHERE id = ?
+`
+
+type SetNoteLocationParams struct {
+	Latitude  sql.NullFloat64
+	Longitude sql.NullFloat64
+	UpdatedAt string
+	ID        string
+}
+
+func (q *Queries) SetNoteLocation(ctx context.Context, arg SetNoteLocationParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteLocation,
+		arg.Latitude,
+		arg.Longitude,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const setNoteProperties = `-- name: SetNoteProperties :exec
+
+UPDATE notes SET properties = ?, updated_at = ?, version = version + 1 WHERE id = ?
+`
+
+type SetNotePropertiesParams struct {
+	Properties string
+	UpdatedAt  string
+	ID         string
+}
+
+func (q *Queries) SetNoteProperties(ctx context.Context, arg SetNotePropertiesParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteProperties, arg.Properties, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setNoteSlug = `-- name: SetNoteSlug :exec
+
+UPDATE notes SET slug = ? WHERE id = ?
+`
+
+type SetNoteSlugParams struct {
+	Slug sql.NullString
+	ID   string
+}
+
+func (q *Queries) SetNoteSlug(ctx context.Context, arg SetNoteSlugParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteSlug, arg.Slug, arg.ID)
+	return err
+}
+
+const setNoteTitle = `-- name: SetNoteTitle :exec
+
+UPDATE notes SET title = ? WHERE id = ? AND title IS NULL
+`
+
+type SetNoteTitleParams struct {
+	Title sql.NullString
+	ID    string
+}
+
+func (q *Queries) SetNoteTitle(ctx context.Context, arg SetNoteTitleParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteTitle, arg.Title, arg.ID)
+	return err
+}
+
+const updateNote = `-- name: UpdateNote :execrows
+
+UPDATE notes SET note = ?, language = ?, title = ?, title_custom = ?, updated_at = ?, version = version + 1
+WHERE id = ? AND version = ?
+`
+
+type UpdateNoteParams struct {
+	Note        string
+	Language    sql.NullString
+	Title       sql.NullString
+	TitleCustom bool
+	UpdatedAt   string
+	ID          string
+	Version     int64
+}
+
+func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateNote,
+		arg.Note,
+		arg.Language,
+		arg.Title,
+		arg.TitleCustom,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -39,6 +39,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
 	return err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+
+DELETE FROM users WHERE id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
 
 SELECT id, created_at, updated_at, name, api_key FROM users WHERE api_key = ?
@@ -56,3 +66,74 @@ func (q *Queries) GetUser(ctx context.Context, apiKey string) (User, error) {
 	)
 	return i, err
 }
+
+const getUserByID = `-- name: GetUserByID :one
+
+SELECT id, created_at, updated_at, name, api_key FROM users WHERE id = ?
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+	)
+	return i, err
+}
+
+const getUsersByAPIKeyPrefix = `-- name: GetUsersByAPIKeyPrefix :many
+
+SELECT id, created_at, updated_at, name, api_key FROM users WHERE api_key LIKE ?
+`
+
+func (q *Queries) GetUsersByAPIKeyPrefix(ctx context.Context, apiKey string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByAPIKeyPrefix, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.ApiKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserAPIKey = `-- name: UpdateUserAPIKey :execrows
+
+UPDATE users SET api_key = ?, updated_at = ? WHERE id = ?
+`
+
+type UpdateUserAPIKeyParams struct {
+	ApiKey    string
+	UpdatedAt string
+	ID        string
+}
+
+func (q *Queries) UpdateUserAPIKey(ctx context.Context, arg UpdateUserAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserAPIKey, arg.ApiKey, arg.UpdatedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	NoteCreated    Type = "note.created"
+	NoteUpdated    Type = "note.updated"
+	ReminderDue    Type = "note.reminder_due"
+	CommentCreated Type = "comment.created"
+	UsageAlert     Type = "usage.alert"
+)
+
+// Types lists every event type the server publishes.
+var Types = []Type{NoteCreated, NoteUpdated, ReminderDue, CommentCreated, UsageAlert}
+
+// Event describes something that happened to a resource owned by UserID.
+// SubjectID identifies the resource, e.g. the note ID for note events or the
+// alert ID for usage alerts.
+type Event struct {
+	Type       Type      `json:"type"`
+	UserID     string    `json:"user_id"`
+	SubjectID  string    `json:"subject_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func New(t Type, userID, subjectID string) Event {
+	return Event{
+		Type:       t,
+		UserID:     userID,
+		SubjectID:  subjectID,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// Handler delivers an event somewhere. It returns an error if the event
+// didn't get there, so the publisher can try again later.
+type Handler func(ctx context.Context, e Event) error
+
+// Bus fans events out to subscribers. Handlers run synchronously in the
+// publisher's goroutine, so that Publish only succeeds once every handler
+// has delivered the event.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+	all      []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[Type][]Handler{}}
+}
+
+// Subscribe registers h for events of type t.
+func (b *Bus) Subscribe(t Type, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], h)
+}
+
+// SubscribeAll registers h for every event.
+func (b *Bus) SubscribeAll(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.all = append(b.all, h)
+}
+
+// Publish hands e to every handler subscribed to it, and returns the errors
+// of those that failed. A failed handler doesn't stop the others.
+func (b *Bus) Publish(ctx context.Context, e Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[e.Type]...), b.all...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		errs = append(errs, h(ctx, e))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,117 @@
+// Package i18n translates API error messages. Each file in catalogs/ maps
+// error codes to messages in one language, named by its language tag. The
+// English catalog is the source of truth: handlers respond with a code, and
+// its English message is what they send.
+//
+// A message ending in a space is a prefix. Handlers append details to it,
+// like a reason or a limit, which are kept as is when translating.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language handlers write messages in, and the
+// fallback for codes other catalogs don't translate.
+const DefaultLanguage = "en"
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// catalogs maps language to code to message.
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded catalogs. Their consistency with the
+// English catalog is checked by the package's tests rather than here, so a
+// bad translation fails CI instead of the server.
+func loadCatalogs() map[string]map[string]string {
+	loaded := map[string]map[string]string{}
+	files, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		log.Printf("i18n: %v", err)
+		return loaded
+	}
+	for _, f := range files {
+		dat, err := catalogFS.ReadFile(path.Join("catalogs", f.Name()))
+		if err != nil {
+			log.Printf("i18n: %s: %v", f.Name(), err)
+			continue
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(dat, &catalog); err != nil {
+			log.Printf("i18n: %s: %v", f.Name(), err)
+			continue
+		}
+		loaded[strings.TrimSuffix(f.Name(), ".json")] = catalog
+	}
+	return loaded
+}
+
+// Message returns the English message for code, or code itself if it isn't
+// in the catalog, so a mistyped code still says something.
+func Message(code string) string {
+	if msg, ok := catalogs[DefaultLanguage][code]; ok {
+		return msg
+	}
+	return code
+}
+
+// IsPrefix reports whether code's message is a prefix that handlers complete
+// with details.
+func IsPrefix(code string) bool {
+	return strings.HasSuffix(catalogs[DefaultLanguage][code], " ")
+}
+
+// Has reports whether code is in the English catalog.
+func Has(code string) bool {
+	_, ok := catalogs[DefaultLanguage][code]
+	return ok
+}
+
+// Translate returns msg, the English message for code, in lang. It returns
+// msg unchanged when lang has no translation for code.
+func Translate(lang, code, msg string) string {
+	translated, ok := catalogs[lang][code]
+	if !ok {
+		return msg
+	}
+	def := catalogs[DefaultLanguage][code]
+	if strings.HasSuffix(def, " ") {
+		if !strings.HasPrefix(msg, def) {
+			return msg
+		}
+		return translated + msg[len(def):]
+	}
+	if msg != def {
+		return msg
+	}
+	return translated
+}
+
+// Negotiate picks the catalog language that best matches an Accept-Language
+// header, comparing primary subtags so that "es-MX" gets Spanish. It falls
+// back to DefaultLanguage.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[lang]; ok && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogsLoaded(t *testing.T) {
+	files, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalogs) != len(files) {
+		t.Errorf("loaded %d catalogs out of %d files", len(catalogs), len(files))
+	}
+	if _, ok := catalogs[DefaultLanguage]; !ok {
+		t.Fatalf("no %s catalog", DefaultLanguage)
+	}
+}
+
+func TestEveryCodeHasADefaultMessage(t *testing.T) {
+	seen := map[string]string{}
+	for code, msg := range catalogs[DefaultLanguage] {
+		if strings.TrimSpace(msg) == "" {
+			t.Errorf("code %s has no default message", code)
+		}
+		if other, ok := seen[msg]; ok {
+			t.Errorf("codes %s and %s share the message %q", code, other, msg)
+		}
+		seen[msg] = code
+	}
+}
+
+func TestCatalogsMatchDefault(t *testing.T) {
+	en := catalogs[DefaultLanguage]
+	for lang, catalog := range catalogs {
+		if lang == DefaultLanguage {
+			continue
+		}
+		for code, msg := range catalog {
+			def, ok := en[code]
+			if !ok {
+				t.Errorf("%s catalog has code %s, which has no default message", lang, code)
+				continue
+			}
+			if strings.TrimSpace(msg) == "" {
+				t.Errorf("%s message for %s is empty", lang, code)
+			}
+			if strings.HasSuffix(msg, " ") != strings.HasSuffix(def, " ") {
+				t.Errorf("%s message for %s must be a prefix exactly when the default is", lang, code)
+			}
+		}
+		for code := range en {
+			if _, ok := catalog[code]; !ok {
+				t.Errorf("%s catalog has no translation for %s", lang, code)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		lang, code, msg, want string
+	}{
+		{lang: "es", code: "note_not_found", msg: "Note not found", want: catalogs["es"]["note_not_found"]},
+		{lang: "es", code: "account_suspended", msg: "Account suspended: spam", want: catalogs["es"]["account_suspended"] + "spam"},
+		// A message that isn't the code's default is left alone.
+		{lang: "es", code: "maintenance", msg: "Back at noon", want: "Back at noon"},
+		{lang: "xx", code: "note_not_found", msg: "Note not found", want: "Note not found"},
+	}
+	for _, tt := range tests {
+		if got := Translate(tt.lang, tt.code, tt.msg); got != tt.want {
+			t.Errorf("Translate(%q, %q, %q) = %q, want %q", tt.lang, tt.code, tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]string{
+		"":                  DefaultLanguage,
+		"es":                "es",
+		"es-MX,en;q=0.5":    "es",
+		"en;q=0.9,es;q=0.8": DefaultLanguage,
+		"fr,es;q=0.3":       "es",
+		"de":                DefaultLanguage,
+		"es;q=bogus":        DefaultLanguage,
+		"ES-es":             "es",
+	}
+	for header, want := range tests {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package ical writes minimal RFC 5545 iCalendar feeds.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const timeFormat = "20060102T150405Z"
+
+type Event struct {
+	UID         string
+	Stamp       time.Time
+	Start       time.Time
+	Summary     string
+	Description string
+}
+
+// Write writes a VCALENDAR containing events to w. Each event gets a display
+// alarm at its start time.
+func Write(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		writeFolded(bw, s)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Notely//Notely//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escape(name))
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escape(e.UID))
+		line("DTSTAMP:" + e.Stamp.UTC().Format(timeFormat))
+		line("DTSTART:" + e.Start.UTC().Format(timeFormat))
+		line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escape(e.Description))
+		}
+		line("BEGIN:VALARM")
+		line("ACTION:DISPLAY")
+		line("DESCRIPTION:" + escape(e.Summary))
+		line("TRIGGER:PT0M")
+		line("END:VALARM")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeFolded writes s terminated by CRLF, folding it into lines of at most
+// 75 octets without splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,95 @@
+// Package ids generates identifiers for new records.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Generator returns a new unique ID on every call. Tests can supply their
+// own to get deterministic IDs.
+type Generator interface {
+	New() string
+}
+
+// V7 generates time-ordered UUIDv7s (RFC 9562): a millisecond timestamp
+// followed by random bits. IDs sort by creation time, which keeps inserts
+// close together in the primary key index. Within one millisecond the 12-bit
+// rand_a field is used as a counter, so IDs from one generator are strictly
+// increasing.
+type V7 struct {
+	mu     sync.Mutex
+	lastMs int64
+	seq    uint16
+	now    func() time.Time
+}
+
+// NewV7 returns a V7 generator using the system clock.
+func NewV7() *V7 {
+	return &V7{now: time.Now}
+}
+
+func (g *V7) New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	switch {
+	case ms > g.lastMs:
+		g.lastMs = ms
+		// Start low so there's room to count up within the millisecond.
+		g.seq = binary.BigEndian.Uint16(b[6:8]) & 0x1ff
+	case g.seq < 0xfff:
+		g.seq++
+	default:
+		// Counter exhausted, or the clock went backwards: borrow the next
+		// millisecond rather than lose ordering.
+		g.lastMs++
+		g.seq = 0
+	}
+	ms, seq := g.lastMs, g.seq
+	g.mu.Unlock()
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(seq>>8)
+	b[7] = byte(seq)
+	b[8] = 0x80 | b[8]&0x3f
+	return uuid.UUID(b).String()
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Slug returns a short random string for use in URLs: 8 random bytes in
+// base58, which leaves out look-alike characters such as 0, O, I and l.
+// Callers must check for collisions.
+func Slug() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	n := binary.BigEndian.Uint64(b[:])
+
+	var out [11]byte
+	i := len(out)
+	for {
+		i--
+		out[i] = base58Alphabet[n%58]
+		n /= 58
+		if n == 0 {
+			break
+		}
+	}
+	return string(out[i:])
+}
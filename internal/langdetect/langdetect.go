@@ -0,0 +1,127 @@
+// Package langdetect guesses the language a short text is written in. It
+// tells apart languages with scripts of their own by script, and languages
+// written in the Latin script by their most common words, which is reliable
+// for a sentence or more but not for a few words.
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Undetermined is the ISO 639 code for text whose language can't be told,
+// such as a URL, a list of numbers or a couple of words.
+const Undetermined = "und"
+
+// minStopwords is how many common words a text needs before its language
+// is trusted, and how far ahead of the runner-up the winner must be.
+const minStopwords = 2
+
+// scripts maps scripts written by a single language, or mostly by one, to
+// that language. Ties go to the one listed first.
+var scripts = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Cyrillic, "ru"},
+}
+
+// stopwords are the most frequent words of each language written in the
+// Latin script, leaving out those shared by several of them.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "are", "of", "to", "with", "that", "this", "for", "it", "you", "have", "not", "be", "at", "from", "my", "we", "they", "will", "what", "can", "would", "there", "been"},
+	"es": {"el", "los", "las", "y", "es", "está", "pero", "muy", "yo", "hay", "más", "cuando", "también", "tengo", "hacer", "nosotros", "eso", "esta", "estoy", "porque", "todo", "hasta", "donde", "sobre", "ahora"},
+	"fr": {"le", "les", "des", "et", "est", "sont", "avec", "pour", "dans", "une", "très", "je", "il", "nous", "vous", "pas", "ce", "sur", "au", "aux", "du", "avoir", "être", "cette", "leur"},
+	"de": {"der", "die", "das", "und", "ist", "sind", "mit", "für", "nicht", "ein", "eine", "ich", "wir", "sie", "auf", "auch", "dem", "zu", "von", "aber", "sehr", "haben", "wenn", "noch", "oder"},
+	"it": {"gli", "della", "è", "sono", "che", "di", "ma", "molto", "io", "noi", "anche", "questo", "nel", "alla", "fare", "ho", "sei", "più", "perché", "per", "lo", "degli", "questa", "sempre", "cosa"},
+	"pt": {"os", "são", "com", "não", "uma", "muito", "eu", "nós", "também", "isso", "na", "em", "ao", "você", "tenho", "fazer", "quando", "pelo", "pela", "seu", "sua", "ainda", "já", "então", "agora"},
+	"nl": {"het", "een", "zijn", "voor", "niet", "ik", "wij", "ze", "op", "ook", "van", "maar", "heel", "hebben", "dat", "dit", "wat", "naar", "er", "bij", "nog", "geen", "wordt", "deze", "kan"},
+}
+
+// languageOf maps each stopword to its language.
+var languageOf = func() map[string]string {
+	m := map[string]string{}
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = lang
+		}
+	}
+	return m
+}()
+
+// Detect returns the ISO 639-1 code of the language text is written in, or
+// Undetermined.
+func Detect(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	counts := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		if lang, ok := languageOf[w]; ok {
+			counts[lang]++
+		}
+	}
+	best, bestCount, runnerUp := Undetermined, 0, 0
+	for lang, n := range counts {
+		switch {
+		case n > bestCount:
+			best, bestCount, runnerUp = lang, n, bestCount
+		case n > runnerUp:
+			runnerUp = n
+		}
+	}
+	if bestCount < minStopwords || bestCount-runnerUp < minStopwords {
+		return Undetermined
+	}
+	return best
+}
+
+// detectScript returns the language of the script most of text's letters
+// are in, or "" if that's the Latin script or text has no letters.
+func detectScript(text string) string {
+	counts := map[string]int{}
+	latin, other := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		other++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.lang]++
+				break
+			}
+		}
+	}
+	if other <= latin {
+		return ""
+	}
+	// Any kana at all makes text Japanese rather than Chinese.
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for _, s := range scripts {
+		if n := counts[s.lang]; n > bestCount {
+			best, bestCount = s.lang, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,123 @@
+// Package moderation checks note content before it is stored.
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// httpTimeout bounds each check by an http moderator. Checks run while the
+// client waits for its note to be saved, so a hung service must not hold
+// requests open.
+const httpTimeout = 5 * time.Second
+
+// Verdict is the outcome of a check. Reason is a short, user-facing
+// explanation when Flagged is true.
+type Verdict struct {
+	Flagged bool   `json:"flagged"`
+	Reason  string `json:"reason"`
+}
+
+type Moderator interface {
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// New returns the moderator of the given kind: "regex" reads one pattern per
+// line from target, "http" posts to the URL in target.
+func New(kind, target string) (Moderator, error) {
+	switch kind {
+	case "regex":
+		return LoadRegexList(target)
+	case "http":
+		if target == "" {
+			return nil, fmt.Errorf("http moderation needs a URL")
+		}
+		return &HTTP{URL: target, Client: &http.Client{Timeout: httpTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown moderation kind %q", kind)
+	}
+}
+
+// RegexList flags text matching any of its patterns.
+type RegexList struct {
+	patterns []*regexp.Regexp
+}
+
+// LoadRegexList reads patterns from path, one per line. Blank lines and lines
+// starting with # are skipped. Patterns are case-insensitive.
+func LoadRegexList(path string) (*RegexList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list := &RegexList{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		list.patterns = append(list.patterns, re)
+	}
+	return list, scanner.Err()
+}
+
+func (l *RegexList) Check(ctx context.Context, text string) (Verdict, error) {
+	for _, re := range l.patterns {
+		if re.MatchString(text) {
+			return Verdict{Flagged: true, Reason: "matched blocked pattern"}, nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+// HTTP posts {"text": ...} to URL and expects a Verdict back as JSON.
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+func (h *HTTP) Check(ctx context.Context, text string) (Verdict, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		dat, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Verdict{}, fmt.Errorf("moderation service responded %d: %s", resp.StatusCode, dat)
+	}
+
+	verdict := Verdict{}
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return Verdict{}, err
+	}
+	return verdict, nil
+}
@@ -0,0 +1,188 @@
+// Package sanitize strips active content from text that may later be
+// rendered as HTML.
+package sanitize
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedAttrs lists the elements that are kept and, for each, the
+// attributes kept on it. Every other element is removed, keeping its text
+// unless it's in dropContent.
+var allowedAttrs = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"details":    nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          nil,
+	"s":          nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// dropContent lists the elements removed along with everything between
+// their tags.
+var dropContent = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+	"noembed":  true,
+	"noframes": true,
+	// plaintext has no end tag: everything after it is dropped.
+	"plaintext": true,
+	"svg":       true,
+	"math":      true,
+}
+
+// urlAttrs are the attributes holding URLs, which must use one of
+// allowedSchemes or be relative.
+var urlAttrs = map[string]bool{"href": true, "src": true}
+
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// HTML keeps only allowlisted elements and attributes in s, and only URLs
+// that are relative or use http, https or mailto. Text outside tags is left
+// exactly as written. It returns the cleaned text and a description of each
+// kind of content removed, in the order first seen.
+func HTML(s string) (string, []string) {
+	var removed []string
+	seen := map[string]bool{}
+	report := func(what string) {
+		if !seen[what] {
+			seen[what] = true
+			removed = append(removed, what)
+		}
+	}
+
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	// While skip is set, everything up to its matching end tag is dropped.
+	skip, depth := "", 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// Reading from a string, the only error is io.EOF.
+			break
+		}
+		token := z.Token()
+
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && token.Data == skip:
+				depth++
+			case tt == html.EndTagToken && token.Data == skip:
+				depth--
+				if depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.Write(z.Raw())
+		case html.CommentToken:
+			report("comment")
+		case html.DoctypeToken:
+			report("doctype")
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			allowed, ok := allowedAttrs[token.Data]
+			if !ok {
+				report(token.Data)
+				if tt == html.StartTagToken && dropContent[token.Data] {
+					skip, depth = token.Data, 1
+				}
+				continue
+			}
+			if tt == html.EndTagToken {
+				b.WriteString("</" + token.Data + ">")
+				continue
+			}
+			b.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				switch {
+				case attr.Namespace != "" || !slices.Contains(allowed, attr.Key):
+					report(attr.Key + " attribute")
+				case urlAttrs[attr.Key] && !safeURL(attr.Val):
+					report(urlScheme(attr.Val) + ": URL")
+				default:
+					b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+				}
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteString(" /")
+			}
+			b.WriteString(">")
+		}
+	}
+	return b.String(), removed
+}
+
+// urlScheme returns the lowercased scheme of an attribute value the
+// tokenizer has already entity-decoded, ignoring whitespace and control
+// characters anywhere in it, as browsers do. It returns "" for relative
+// URLs.
+func urlScheme(val string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, val)
+	i := strings.IndexAny(cleaned, ":/?#")
+	if i <= 0 || cleaned[i] != ':' {
+		return ""
+	}
+	return strings.ToLower(cleaned[:i])
+}
+
+func safeURL(val string) bool {
+	scheme := urlScheme(val)
+	return scheme == "" || allowedSchemes[scheme]
+}
@@ -0,0 +1,114 @@
+package sanitize
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		want        string
+		wantRemoved []string
+	}{
+		{
+			name: "plain markdown",
+			in:   "# Shopping\n\n- milk & eggs\n- 2 < 3 and 3 > 2 <3",
+			want: "# Shopping\n\n- milk & eggs\n- 2 < 3 and 3 > 2 <3",
+		},
+		{
+			name: "allowed markup",
+			in:   `<p>See <a href="https://example.com/?a=1&amp;b=2" title="x">this</a><br/></p>`,
+			want: `<p>See <a href="https://example.com/?a=1&amp;b=2" title="x">this</a><br /></p>`,
+		},
+		{
+			name:        "script element",
+			in:          "before<script>alert(1)</script>after",
+			want:        "beforeafter",
+			wantRemoved: []string{"script"},
+		},
+		{
+			name:        "nested drop element",
+			in:          "a<svg><svg></svg><script>alert(1)</script></svg>b",
+			want:        "ab",
+			wantRemoved: []string{"svg"},
+		},
+		{
+			name:        "slash before event handler",
+			in:          `<img/onerror=alert(1) src=x>`,
+			want:        `<img src="x">`,
+			wantRemoved: []string{"onerror attribute"},
+		},
+		{
+			name:        "entity-encoded javascript URL",
+			in:          `<a href="jav&#x61;script:alert(1)">x</a>`,
+			want:        `<a>x</a>`,
+			wantRemoved: []string{"javascript: URL"},
+		},
+		{
+			name:        "tab inside javascript URL",
+			in:          "<a href=\"java\tscript:alert(1)\">x</a>",
+			want:        `<a>x</a>`,
+			wantRemoved: []string{"javascript: URL"},
+		},
+		{
+			name:        "encoded tab and leading space",
+			in:          `<a href=" java&#9;script:alert(1)">x</a>`,
+			want:        `<a>x</a>`,
+			wantRemoved: []string{"javascript: URL"},
+		},
+		{
+			name:        "meta refresh",
+			in:          `<meta http-equiv="refresh" content="0;url=javascript:alert(1)">hi`,
+			want:        "hi",
+			wantRemoved: []string{"meta"},
+		},
+		{
+			name:        "data URL",
+			in:          `<img src="data:text/html;base64,PHNjcmlwdD4=">`,
+			want:        `<img>`,
+			wantRemoved: []string{"data: URL"},
+		},
+		{
+			name:        "unknown element keeps its text",
+			in:          `<font color="red">hi</font>`,
+			want:        "hi",
+			wantRemoved: []string{"font"},
+		},
+		{
+			name:        "style attribute",
+			in:          `<p style="background:url(javascript:alert(1))">x</p>`,
+			want:        `<p>x</p>`,
+			wantRemoved: []string{"style attribute"},
+		},
+		{
+			name:        "plaintext hides the rest",
+			in:          "a<plaintext><script>alert(1)</script>",
+			want:        "a",
+			wantRemoved: []string{"plaintext"},
+		},
+		{
+			name:        "comment",
+			in:          "a<!--[if IE]><script>alert(1)</script><![endif]-->b",
+			want:        "ab",
+			wantRemoved: []string{"comment"},
+		},
+		{
+			name: "relative and mailto URLs",
+			in:   `<a href="/notes/1">a</a><a href="mailto:me@example.com">b</a>`,
+			want: `<a href="/notes/1">a</a><a href="mailto:me@example.com">b</a>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := HTML(tt.in)
+			if got != tt.want {
+				t.Errorf("HTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !slices.Equal(removed, tt.wantRemoved) {
+				t.Errorf("HTML(%q) removed %q, want %q", tt.in, removed, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package singleflight collapses concurrent calls for the same key into one.
+// It is a small typed take on golang.org/x/sync/singleflight.
+package singleflight
+
+import "sync"
+
+type call[T any] struct {
+	wg  sync.WaitGroup
+	val T
+	err error
+}
+
+// Group runs at most one fn per key at a time. The zero value is ready to
+// use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// Do calls fn unless a call for key is already in flight, in which case it
+// waits for that call and returns its result. shared reports whether the
+// result was handed to more than one caller; callers that got a shared
+// result must not modify it.
+func (g *Group[T]) Do(key string, fn func() (T, error)) (val T, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call[T]{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &call[T]{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
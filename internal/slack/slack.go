@@ -0,0 +1,73 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Message is a Slack incoming-webhook payload. Text is the plain fallback
+// shown in notifications; Blocks is the Block Kit layout.
+type Message struct {
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+type Block struct {
+	Type string `json:"type"`
+	Text *Text  `json:"text,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Section returns a section block with mrkdwn text.
+func Section(markdown string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: markdown}}
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape makes s safe to put in mrkdwn text, so that user content can't
+// mention @channel or disguise a link.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// ValidWebhookURL reports whether u looks like a Slack incoming webhook.
+func ValidWebhookURL(u string) bool {
+	parsed, err := url.Parse(u)
+	return err == nil && parsed.Scheme == "https" && parsed.Host == "hooks.slack.com"
+}
+
+func Post(ctx context.Context, client *http.Client, webhookURL string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		dat, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack responded %d: %s", resp.StatusCode, dat)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package statsd is a minimal StatsD client with DogStatsD-style tags.
+// Metrics are sent over UDP and dropped on error; metrics must never fail a
+// request.
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client sends metrics to a StatsD agent. A nil *Client discards everything,
+// so callers don't need to check whether metrics are enabled.
+type Client struct {
+	conn   net.Conn
+	prefix string
+}
+
+// New returns a client sending to addr (host:port). prefix is prepended to
+// every metric name, e.g. "notely." gives "notely.requests".
+func New(addr, prefix string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, prefix: prefix}, nil
+}
+
+// Count adds value to a counter. tags are "key:value" pairs.
+func (c *Client) Count(name string, value int64, tags ...string) {
+	c.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Timing records a duration in milliseconds.
+func (c *Client) Timing(name string, d time.Duration, tags ...string) {
+	c.send(name, strconv.FormatInt(d.Milliseconds(), 10), "ms", tags)
+}
+
+func (c *Client) send(name, value, kind string, tags []string) {
+	if c == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(c.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(tags, ","))
+	}
+	c.conn.Write([]byte(b.String()))
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
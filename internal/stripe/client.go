@@ -0,0 +1,139 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const apiBase = "https://api.stripe.com/v1"
+
+// Client calls the Stripe API with a secret key. It only covers creating
+// Checkout and customer portal sessions.
+type Client struct {
+	key  string
+	base string
+	http *http.Client
+}
+
+func NewClient(secretKey string) *Client {
+	return &Client{
+		key:  secretKey,
+		base: apiBase,
+		http: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CheckoutSession is a Checkout session, as created by the API or delivered
+// with checkout.session.completed events.
+type CheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	Mode              string            `json:"mode"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	PaymentStatus     string            `json:"payment_status"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// Paid reports whether the session's payment went through. Sessions that
+// need no payment up front, such as ones starting a trial, count as paid.
+func (s CheckoutSession) Paid() bool {
+	return s.PaymentStatus == "paid" || s.PaymentStatus == "no_payment_required"
+}
+
+type CheckoutParams struct {
+	PriceID    string
+	SuccessURL string
+	CancelURL  string
+	// UserID is stored on the session and the subscription it creates, so
+	// webhook events can be matched to the user.
+	UserID string
+	// CustomerID reuses an existing Stripe customer; without it Checkout
+	// creates one.
+	CustomerID string
+}
+
+// CreateCheckoutSession starts a subscription Checkout for one unit of
+// PriceID. Send the user to the returned session's URL.
+func (c *Client) CreateCheckoutSession(ctx context.Context, params CheckoutParams) (CheckoutSession, error) {
+	form := url.Values{
+		"mode":                                 {"subscription"},
+		"line_items[0][price]":                 {params.PriceID},
+		"line_items[0][quantity]":              {"1"},
+		"success_url":                          {params.SuccessURL},
+		"cancel_url":                           {params.CancelURL},
+		"client_reference_id":                  {params.UserID},
+		"metadata[user_id]":                    {params.UserID},
+		"subscription_data[metadata][user_id]": {params.UserID},
+	}
+	if params.CustomerID != "" {
+		form.Set("customer", params.CustomerID)
+	}
+	var session CheckoutSession
+	err := c.post(ctx, "/checkout/sessions", form, &session)
+	return session, err
+}
+
+type PortalSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreatePortalSession opens the customer portal, where customerID can
+// manage or cancel their subscription, returning to returnURL when done.
+func (c *Client) CreatePortalSession(ctx context.Context, customerID, returnURL string) (PortalSession, error) {
+	var session PortalSession
+	err := c.post(ctx, "/billing_portal/sessions", url.Values{
+		"customer":   {customerID},
+		"return_url": {returnURL},
+	}, &session)
+	return session, err
+}
+
+// APIError is an error response from the Stripe API.
+type APIError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("stripe: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+func (c *Client) post(ctx context.Context, path string, form url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error APIError `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return fmt.Errorf("stripe %s: status %d", path, resp.StatusCode)
+		}
+		body.Error.StatusCode = resp.StatusCode
+		return &body.Error
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("stripe %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package stripe covers the small part of the Stripe API Notely uses:
+// verifying and decoding webhook events, and starting Checkout and customer
+// portal sessions.
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how old a webhook signature may be, which limits
+// replays of captured requests.
+const SignatureTolerance = 5 * time.Minute
+
+var (
+	ErrInvalidSignature = errors.New("invalid Stripe signature")
+	ErrExpiredSignature = errors.New("Stripe signature timestamp is too old")
+)
+
+// Event is a webhook event. Data.Object holds the object the event is about,
+// e.g. a Subscription for customer.subscription.* events.
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type Subscription struct {
+	ID       string            `json:"id"`
+	Customer string            `json:"customer"`
+	Status   string            `json:"status"`
+	Metadata map[string]string `json:"metadata"`
+	Items    struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// HasPrice reports whether the subscription includes priceID.
+func (s Subscription) HasPrice(priceID string) bool {
+	for _, item := range s.Items.Data {
+		if item.Price.ID == priceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the subscription should grant access. Past-due
+// subscriptions are still being retried by Stripe, so they count.
+func (s Subscription) Active() bool {
+	switch s.Status {
+	case "active", "trialing", "past_due":
+		return true
+	}
+	return false
+}
+
+// ParseWebhook checks the Stripe-Signature header of a webhook request
+// against the endpoint's signing secret and decodes the event.
+func ParseWebhook(payload []byte, header, secret string, now time.Time) (Event, error) {
+	if err := verifySignature(payload, header, secret, now); err != nil {
+		return Event{}, err
+	}
+	var event Event
+	err := json.Unmarshal(payload, &event)
+	return event, err
+}
+
+// verifySignature implements Stripe's scheme: the header holds a timestamp
+// t and one or more v1 signatures, each an HMAC-SHA256 of "t.payload".
+func verifySignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sig, err := hex.DecodeString(value)
+			if err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	t, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			if now.Sub(time.Unix(t, 0)) > SignatureTolerance {
+				return ErrExpiredSignature
+			}
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/go-chi/chi"
+)
+
+// newInternalRouter serves the operational endpoints that should never be
+// reachable from the internet: the admin API, under the same /v1/admin paths
+// as on the public port, and pprof. It is meant to be bound to a port that is
+// firewalled off.
+func (cfg *apiConfig) newInternalRouter(admin http.Handler) http.Handler {
+	router := chi.NewRouter()
+	router.Use(cfg.drain.middleware)
+
+	router.Mount("/v1/admin", admin)
+	router.Get("/v1/readyz", cfg.handlerReadyz)
+
+	router.HandleFunc("/debug/pprof/", pprof.Index)
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	router.Handle("/debug/pprof/{profile}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pprof.Handler(chi.URLParam(r, "profile")).ServeHTTP(w, r)
+	}))
+	return router
+}
@@ -1,34 +1,112 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"sync"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
 )
 
-func respondWithError(w http.ResponseWriter, code int, msg string, logErr error) {
+// marshalErrorBody is sent when a payload can't be marshalled, so clients
+// still get a well-formed error instead of an empty response.
+var marshalErrorBody = []byte(`{"error":"Couldn't encode response","code":"encode_response_failed"}`)
+
+// respondWithError responds with the catalog message for code.
+func respondWithError(w http.ResponseWriter, status int, code string, logErr error) {
+	respondWithErrorDetail(w, status, code, "", logErr)
+}
+
+// respondWithErrorDetail responds with the catalog message for code followed
+// by detail, for codes whose message is a prefix.
+func respondWithErrorDetail(w http.ResponseWriter, status int, code, detail string, logErr error) {
+	// With the circuit breaker open, tell clients to come back rather than
+	// reporting a server bug or a missing record.
+	if errors.Is(logErr, errDBUnavailable) {
+		status = http.StatusServiceUnavailable
+		w.Header().Set("Retry-After", "30")
+	}
 	if logErr != nil {
 		log.Println(logErr)
 	}
-	if code > 499 {
+	msg := i18n.Message(code) + detail
+	if status > 499 {
 		log.Printf("Responding with 5XX error: %s", msg)
 	}
-	type errorResponse struct {
-		Error string `json:"error"`
-	}
-	respondWithJSON(w, code, errorResponse{
+	respondWithJSON(w, status, errorResponse{
 		Error: msg,
+		Code:  code,
 	})
 }
 
+// respondWithAPIError responds with err's code and message when it's a
+// clientError, and with a 500 otherwise, since only clientErrors are worded
+// for clients.
+func respondWithAPIError(w http.ResponseWriter, status int, err error) {
+	var clientErr clientError
+	if !errors.As(err, &clientErr) {
+		respondWithError(w, http.StatusInternalServerError, "internal_error", err)
+		return
+	}
+	code, detail := clientErr.errorCode()
+	respondWithErrorDetail(w, status, code, detail, nil)
+}
+
+// clientError is an error meant for clients. Its message is the catalog
+// message for code, followed by detail when that message is a prefix.
+type clientError interface {
+	error
+	errorCode() (code, detail string)
+}
+
+// apiError is a clientError for failures that need no type of their own.
+type apiError struct {
+	code   string
+	detail string
+}
+
+func (e *apiError) Error() string {
+	return i18n.Message(e.code) + e.detail
+}
+
+func (e *apiError) errorCode() (code, detail string) {
+	return e.code, e.detail
+}
+
+// errorResponse is the body of every error. Code identifies the message
+// independently of its wording, so clients can match on it.
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// Response buffers are reused between requests. Buffers that grew past
+// maxPooledBufferSize for one large response aren't kept.
+const maxPooledBufferSize = 64 << 10
+
+var jsonBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	buf := jsonBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			jsonBufferPool.Put(buf)
+		}
+	}()
+
 	w.Header().Set("Content-Type", "application/json")
-	dat, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("Error marshalling JSON: %s", err)
-		w.WriteHeader(500)
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		log.Printf("Error marshalling JSON for %T: %s", payload, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(marshalErrorBody)
 		return
 	}
 	w.WriteHeader(code)
-	w.Write(dat)
+	w.Write(buf.Bytes())
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// jsonArrayWriter streams a JSON array to the client one element at a time,
+// so large responses never have to be held in memory. Once the first byte is
+// written the status code is fixed, so errors after that point can only be
+// logged and the response cut short.
+type jsonArrayWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	count   int
+}
+
+func newJSONArrayWriter(w http.ResponseWriter, code int) (*jsonArrayWriter, error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write([]byte("[")); err != nil {
+		return nil, err
+	}
+	flusher, _ := w.(http.Flusher)
+	return &jsonArrayWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}, nil
+}
+
+func (a *jsonArrayWriter) Write(v interface{}) error {
+	if a.count > 0 {
+		if _, err := a.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	a.count++
+	return a.enc.Encode(v)
+}
+
+func (a *jsonArrayWriter) Flush() {
+	if a.flusher != nil {
+		a.flusher.Flush()
+	}
+}
+
+func (a *jsonArrayWriter) Close() error {
+	_, err := a.w.Write([]byte("]"))
+	return err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespondWithJSONUnmarshalablePayloads(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload any
+	}{
+		{name: "channel", payload: make(chan int)},
+		{name: "NaN", payload: math.NaN()},
+		{name: "infinity", payload: math.Inf(1)},
+		{name: "channel in a struct", payload: struct {
+			Name string   `json:"name"`
+			C    chan int `json:"c"`
+		}{Name: "partial", C: make(chan int)}},
+		{name: "NaN in a slice", payload: []float64{1, math.NaN()}},
+		{name: "function", payload: map[string]any{"f": func() {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respondWithJSON(w, http.StatusOK, tt.payload)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if w.Body.String() != string(marshalErrorBody) {
+				t.Errorf("body = %q, want %q", w.Body, marshalErrorBody)
+			}
+		})
+	}
+}
+
+func TestRespondWithJSONAfterMarshalFailure(t *testing.T) {
+	// A failed encode must not leave anything in the pooled buffer for the
+	// next response.
+	respondWithJSON(httptest.NewRecorder(), http.StatusOK, []any{"first", make(chan int)})
+
+	w := httptest.NewRecorder()
+	respondWithJSON(w, http.StatusCreated, map[string]string{"ok": "yes"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	got := map[string]string{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body %q isn't JSON: %v", w.Body, err)
+	}
+	if got["ok"] != "yes" || len(got) != 1 {
+		t.Errorf("body = %q", w.Body)
+	}
+}
+
+// discardResponseWriter is a ResponseWriter that allocates nothing, so
+// benchmarks measure only the encoding.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func benchmarkNoteList(b *testing.B, count int) []Note {
+	notes := make([]Note, count)
+	for i := range notes {
+		note, err := databaseNoteToNote(testNote)
+		if err != nil {
+			b.Fatal(err)
+		}
+		notes[i] = note
+	}
+	return notes
+}
+
+// BenchmarkRespondWithJSONNoteList measures encoding a note list the way
+// GET /v1/notes does, into a pooled buffer.
+func BenchmarkRespondWithJSONNoteList(b *testing.B) {
+	for _, count := range []int{1, 50, 1000} {
+		notes := benchmarkNoteList(b, count)
+		b.Run(fmt.Sprintf("notes=%d", count), func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				respondWithJSON(w, http.StatusOK, notes)
+			}
+		})
+	}
+}
+
+// BenchmarkMarshalNoteList is the same work with json.Marshal allocating a
+// fresh slice per response, as respondWithJSON used to, for comparison.
+func BenchmarkMarshalNoteList(b *testing.B) {
+	for _, count := range []int{1, 50, 1000} {
+		notes := benchmarkNoteList(b, count)
+		b.Run(fmt.Sprintf("notes=%d", count), func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dat, err := json.Marshal(notes)
+				if err != nil {
+					b.Fatal(err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write(dat)
+			}
+		})
+	}
+}
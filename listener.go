@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd; 0-2 are
+// stdin, stdout and stderr.
+const listenFDsStart = 3
+
+// listen returns the listener to serve on: a socket passed by systemd, a
+// Unix socket at LISTEN_SOCKET, or TCP on PORT, in that order.
+func listen() (net.Listener, error) {
+	ln, err := activationListener()
+	if ln != nil || err != nil {
+		return ln, err
+	}
+
+	if path := os.Getenv("LISTEN_SOCKET"); path != "" {
+		mode, err := listenSocketMode()
+		if err != nil {
+			return nil, err
+		}
+		return unixListener(path, mode)
+	}
+
+	port, err := listenPort()
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen("tcp", ":"+port)
+}
+
+// listenTarget describes what listen would serve on, checking the same
+// settings in the same order without opening anything.
+func listenTarget() (string, error) {
+	if activated, err := socketActivated(); activated || err != nil {
+		return "socket passed by systemd", err
+	}
+	if path := os.Getenv("LISTEN_SOCKET"); path != "" {
+		if _, err := listenSocketMode(); err != nil {
+			return "", err
+		}
+		return "unix socket " + path, nil
+	}
+	port, err := listenPort()
+	if err != nil {
+		return "", err
+	}
+	return "tcp port " + port, nil
+}
+
+func listenSocketMode() (os.FileMode, error) {
+	s := os.Getenv("LISTEN_SOCKET_MODE")
+	if s == "" {
+		return 0o660, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("LISTEN_SOCKET_MODE must be an octal file mode: %w", err)
+	}
+	return os.FileMode(mode), nil
+}
+
+func listenPort() (string, error) {
+	port := os.Getenv("PORT")
+	if port == "" {
+		return "", errors.New("PORT environment variable is not set")
+	}
+	if _, err := net.LookupPort("tcp", port); err != nil {
+		return "", fmt.Errorf("PORT: %w", err)
+	}
+	return port, nil
+}
+
+// unixListener listens on a Unix socket at path with the given permissions,
+// replacing a socket left behind by a previous process. The socket file is
+// removed when the listener is closed.
+func unixListener(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("LISTEN_SOCKET %s exists and isn't a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// activationListener returns the listener passed in by systemd socket
+// activation, or nil if the process wasn't socket activated. See
+// sd_listen_fds(3). Only the first socket is used.
+func activationListener() (net.Listener, error) {
+	if activated, err := socketActivated(); !activated || err != nil {
+		return nil, err
+	}
+
+	// Children, such as a restart wrapper's next process, must not think
+	// the sockets are meant for them.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	syscall.CloseOnExec(listenFDsStart)
+	f := os.NewFile(listenFDsStart, "listen-fd")
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't use socket passed by systemd: %w", err)
+	}
+	return ln, nil
+}
+
+// socketActivated reports whether systemd passed this process its sockets.
+func socketActivated() (bool, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return false, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return false, errors.New("LISTEN_PID is set but LISTEN_FDS has no sockets")
+	}
+	return true, nil
+}
+
+// shutdownOnSignal calls shutdown on SIGTERM or SIGINT, so a restart lets
+// in-flight requests finish instead of cutting them off.
+func shutdownOnSignal(shutdown func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	<-sig
+	shutdown()
+}
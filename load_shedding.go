@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	shedWindow     = 30 * time.Second
+	shedMaxSamples = 1024
+	// Below this many samples in the window the signal is too noisy to act on.
+	shedMinSamples = 20
+	shedRetryAfter = "30"
+)
+
+type shedSample struct {
+	at    time.Time
+	value time.Duration
+	bad   bool
+}
+
+// sampleRing keeps the most recent samples, dropping the oldest when full.
+type sampleRing struct {
+	samples [shedMaxSamples]shedSample
+	next    int
+	n       int
+}
+
+func (r *sampleRing) add(s shedSample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % shedMaxSamples
+	if r.n < shedMaxSamples {
+		r.n++
+	}
+}
+
+func (r *sampleRing) since(cutoff time.Time) []shedSample {
+	var out []shedSample
+	for i := 0; i < r.n; i++ {
+		if s := r.samples[i]; s.at.After(cutoff) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// loadShedder watches request latency and database errors over a rolling
+// window. While either is over its threshold, low-priority routes are
+// rejected with 503 so core CRUD keeps its capacity.
+type loadShedder struct {
+	mu           sync.Mutex
+	p99Limit     time.Duration
+	errRateLimit float64
+
+	latencies sampleRing
+	dbCalls   sampleRing
+}
+
+type shedState struct {
+	Shedding    bool    `json:"shedding"`
+	P99Ms       int64   `json:"p99_ms"`
+	DBErrorRate float64 `json:"db_error_rate"`
+}
+
+func newLoadShedder(p99Limit time.Duration, errRateLimit float64) *loadShedder {
+	return &loadShedder{p99Limit: p99Limit, errRateLimit: errRateLimit}
+}
+
+// setLimits replaces the thresholds, keeping the samples collected so far.
+func (s *loadShedder) setLimits(p99Limit time.Duration, errRateLimit float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.p99Limit = p99Limit
+	s.errRateLimit = errRateLimit
+}
+
+func (s *loadShedder) recordLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies.add(shedSample{at: time.Now(), value: d})
+}
+
+func (s *loadShedder) recordDB(err error) {
+	bad := err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, context.Canceled)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dbCalls.add(shedSample{at: time.Now(), bad: bad})
+}
+
+func (s *loadShedder) state() shedState {
+	cutoff := time.Now().Add(-shedWindow)
+	s.mu.Lock()
+	latencies := s.latencies.since(cutoff)
+	dbCalls := s.dbCalls.since(cutoff)
+	p99Limit, errRateLimit := s.p99Limit, s.errRateLimit
+	s.mu.Unlock()
+
+	state := shedState{}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i].value < latencies[j].value })
+		p99 := latencies[(len(latencies)*99)/100].value
+		state.P99Ms = p99.Milliseconds()
+		if len(latencies) >= shedMinSamples && p99 > p99Limit {
+			state.Shedding = true
+		}
+	}
+	if len(dbCalls) > 0 {
+		var bad int
+		for _, c := range dbCalls {
+			if c.bad {
+				bad++
+			}
+		}
+		state.DBErrorRate = float64(bad) / float64(len(dbCalls))
+		if len(dbCalls) >= shedMinSamples && state.DBErrorRate > errRateLimit {
+			state.Shedding = true
+		}
+	}
+	return state
+}
+
+type shedSkipKey struct{}
+
+// observe records the latency of every request except low-priority ones,
+// whose slowness is expected and shouldn't cause shedding.
+func (s *loadShedder) observe(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip := new(bool)
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), shedSkipKey{}, skip)))
+		if !*skip {
+			s.recordLatency(time.Since(start))
+		}
+	})
+}
+
+// lowPriority marks a route as sheddable.
+func (s *loadShedder) lowPriority(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if skip, ok := r.Context().Value(shedSkipKey{}).(*bool); ok {
+			*skip = true
+		}
+		if s.state().Shedding {
+			w.Header().Set("Retry-After", shedRetryAfter)
+			respondWithError(w, http.StatusServiceUnavailable, "server_overloaded", nil)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// observedDB reports the outcome of every database call to the shedder.
+type observedDB struct {
+	db      database.DBTX
+	shedder *loadShedder
+}
+
+func (o observedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := o.db.ExecContext(ctx, query, args...)
+	o.shedder.recordDB(err)
+	return result, err
+}
+
+func (o observedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	stmt, err := o.db.PrepareContext(ctx, query)
+	o.shedder.recordDB(err)
+	return stmt, err
+}
+
+func (o observedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	o.shedder.recordDB(err)
+	return rows, err
+}
+
+func (o observedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := o.db.QueryRowContext(ctx, query, args...)
+	o.shedder.recordDB(row.Err())
+	return row
+}
+
+func (o observedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := beginTx(ctx, o.db, opts)
+	o.shedder.recordDB(err)
+	return tx, err
+}
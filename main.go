@@ -1,41 +1,194 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
-	"io"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
 
+	"github.com/bootdotdev/learn-cicd-starter/internal/analytics"
+	"github.com/bootdotdev/learn-cicd-starter/internal/broker"
 	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+	"github.com/bootdotdev/learn-cicd-starter/internal/ids"
+	"github.com/bootdotdev/learn-cicd-starter/internal/moderation"
+	"github.com/bootdotdev/learn-cicd-starter/internal/singleflight"
+	"github.com/bootdotdev/learn-cicd-starter/internal/statsd"
+	"github.com/bootdotdev/learn-cicd-starter/internal/stripe"
+	"github.com/bootdotdev/learn-cicd-starter/internal/telegram"
 
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
 
 type apiConfig struct {
-	DB *database.Queries
+	DB                 *database.Queries
+	db                 database.DBTX
+	wrapDB             func(database.DBTX) database.DBTX
+	adminAPIKey        string
+	maintenance        atomic.Pointer[maintenanceState]
+	events             *events.Bus
+	telegram           *telegram.Client
+	runtime            atomic.Pointer[runtimeConfig]
+	moderator          moderation.Moderator
+	moderationAction   string
+	erasureGracePeriod time.Duration
+	shedder            *loadShedder
+	metrics            *statsd.Client
+	noteLists          singleflight.Group[[]database.Note]
+	staleUsers         staleCache[database.User]
+	staleSuspensions   staleCache[suspensionStatus]
+	staleNoteLists     staleCache[[]database.Note]
+	drain              drainState
+	ids                ids.Generator
+	rateLimiter        *userRateLimiter
+	enforcePlans       bool
+	plans              planCache
+	stats              statsCache
+	stripe             stripeConfig
+	usage              usageTracker
+	requestLog         *requestLog
 }
 
 //go:embed static/*
 var staticFiles embed.FS
 
 func main() {
+	checkConfig := flag.Bool("check-config", false, "validate configuration, print a JSON report and exit")
+	mock := flag.Bool("mock", false, "serve users and notes from memory with fixture data, without a database")
+	mockLatency := flag.Duration("mock-latency", 0, "with -mock, delay every request by this long")
+	mockErrorPercent := flag.Int("mock-error-percent", 0, "with -mock, fail this percentage of API requests with a 503")
+	flag.Parse()
+
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Printf("warning: assuming default configuration. .env unreadable: %v", err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		log.Fatal("PORT environment variable is not set")
+	// Go time layouts, separated by semicolons since layouts can contain
+	// commas.
+	if layouts := os.Getenv("DB_TIME_LAYOUTS"); layouts != "" {
+		dbTimeLayouts = append(dbTimeLayouts, strings.Split(layouts, ";")...)
+	}
+
+	if *checkConfig {
+		if !runConfigCheck(os.Stdout, os.Getenv("DATABASE_URL")) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *mock {
+		frontend, err := frontendFS()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Fatal(runMockServer(frontend, *mockLatency, *mockErrorPercent))
+	}
+
+	if flag.Arg(0) == "keys" {
+		dbURL := os.Getenv("DATABASE_URL")
+		if dbURL == "" {
+			log.Fatal("DATABASE_URL environment variable is not set")
+		}
+		db, err := sql.Open("libsql", dbURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		if err := runKeysCommand(context.Background(), database.New(db), flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	listener, err := listen()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	apiCfg := apiConfig{
+		adminAPIKey:        os.Getenv("ADMIN_API_KEY"),
+		events:             events.NewBus(),
+		ids:                ids.NewV7(),
+		erasureGracePeriod: time.Duration(getEnvInt("ERASURE_GRACE_DAYS", 30)) * 24 * time.Hour,
+	}
+	runtimeCfg, err := loadRuntimeConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiCfg.runtime.Store(runtimeCfg)
+	apiCfg.shedder = newLoadShedder(runtimeCfg.ShedP99, runtimeCfg.ShedDBErrorRate)
+	apiCfg.rateLimiter = newUserRateLimiter(time.Minute)
+	apiCfg.staleNoteLists.weigh = noteListBytes
+	apiCfg.enforcePlans = getEnvBool("ENFORCE_PLANS", false)
+	apiCfg.stripe = stripeConfig{
+		webhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		proPriceID:    os.Getenv("STRIPE_PRO_PRICE_ID"),
+		returnURL:     os.Getenv("STRIPE_RETURN_URL"),
+	}
+	if key := os.Getenv("STRIPE_SECRET_KEY"); key != "" {
+		apiCfg.stripe.client = stripe.NewClient(key)
+	}
+	go apiCfg.reloadOnSIGHUP()
+	apiCfg.events.Subscribe(events.ReminderDue, logEvent)
+
+	if brokerKind := os.Getenv("BROKER"); brokerKind != "" {
+		pub, err := broker.New(brokerKind, os.Getenv("BROKER_URL"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer pub.Close()
+		prefix := os.Getenv("BROKER_SUBJECT_PREFIX")
+		if prefix == "" {
+			prefix = "notely"
+		}
+		apiCfg.events.SubscribeAll(brokerForwarder(pub, prefix))
+		log.Printf("Publishing events to %s", brokerKind)
 	}
 
-	apiCfg := apiConfig{}
+	if kind := os.Getenv("MODERATION"); kind != "" {
+		apiCfg.moderator, err = moderation.New(kind, os.Getenv("MODERATION_SOURCE"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		apiCfg.moderationAction, err = moderationActionFromEnv()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Moderating notes with %s (%s)", kind, apiCfg.moderationAction)
+	}
+
+	backend, err := metricsBackendFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if backend == "statsd" {
+		addr := os.Getenv("STATSD_ADDR")
+		if addr == "" {
+			addr = "127.0.0.1:8125"
+		}
+		apiCfg.metrics, err = statsd.New(addr, "notely.")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer apiCfg.metrics.Close()
+		log.Printf("Sending metrics to statsd at %s", addr)
+	}
 
 	// https://github.com/libsql/libsql-client-go/#open-a-connection-to-sqld
 	// libsql://[your-database].turso.io?authToken=[your-auth-token]
@@ -48,51 +201,320 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
-		dbQueries := database.New(db)
+		retryAttempts := getEnvInt("DB_RETRY_ATTEMPTS", 3)
+		breaker := newDBBreaker(
+			getEnvInt("DB_BREAKER_FAILURES", 5),
+			time.Duration(getEnvInt("DB_BREAKER_COOLDOWN_SECONDS", 30))*time.Second,
+			apiCfg.metrics,
+		)
+		// Transactions go through the same retries, breaker and shedder as
+		// the database itself.
+		apiCfg.wrapDB = func(db database.DBTX) database.DBTX {
+			retrier := newDBRetry(db, retryAttempts, apiCfg.metrics)
+			return observedDB{db: breaker.wrap(retrier), shedder: apiCfg.shedder}
+		}
+		apiCfg.db = apiCfg.wrapDB(db)
+		dbQueries := database.New(apiCfg.db)
 		apiCfg.DB = dbQueries
 		log.Println("Connected to database!")
+
+		if err := apiCfg.loadMaintenanceState(context.Background()); err != nil {
+			log.Printf("Couldn't load maintenance state: %v", err)
+		}
+
+		apiCfg.events.SubscribeAll(apiCfg.notifySlack)
+
+		go runPeriodically(context.Background(), "reminders", time.Minute, apiCfg.sendDueReminders)
+		go runPeriodically(context.Background(), "schedules", time.Minute, apiCfg.runSchedules)
+		go runPeriodically(context.Background(), "outbox", time.Second, apiCfg.dispatchOutbox)
+
+		// Jobs left running by a previous process will never finish; retry them.
+		if err := dbQueries.ResetRunningExports(context.Background(), time.Now().UTC().Format(time.RFC3339)); err != nil {
+			log.Printf("Couldn't reset running exports: %v", err)
+		}
+		go runPeriodically(context.Background(), "exports", 5*time.Second, apiCfg.runExports)
+		go runPeriodically(context.Background(), "erasures", time.Hour, apiCfg.runErasures)
+		go runPeriodically(context.Background(), "sessions", time.Hour, apiCfg.deleteExpiredSessions)
+		go runPeriodically(context.Background(), "slugs", time.Minute, apiCfg.backfillNoteSlugs)
+		go runPeriodically(context.Background(), "languages", time.Minute, apiCfg.backfillNoteLanguages)
+		go runPeriodically(context.Background(), "titles", time.Minute, apiCfg.backfillNoteTitles)
+		go runPeriodically(context.Background(), "usage", time.Minute, apiCfg.flushUsage)
+		go runPeriodically(context.Background(), "usage-alerts", usageAlertInterval, apiCfg.checkUsageAlerts)
+
+		if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+			apiCfg.telegram = telegram.New(token)
+			go apiCfg.runTelegramBot(context.Background(), apiCfg.telegram)
+			log.Println("Telegram bot enabled")
+		}
+	}
+
+	// Each expensive route gets its own limiter so a burst on one can't
+	// starve the others. A limit of 0 disables limiting.
+	routeConcurrency := getEnvInt("ROUTE_CONCURRENCY_LIMIT", 0)
+	routeQueue := getEnvInt("ROUTE_CONCURRENCY_QUEUE", routeConcurrency)
+	limitRoute := func(handler http.HandlerFunc) http.HandlerFunc {
+		if routeConcurrency <= 0 {
+			return handler
+		}
+		return newConcurrencyLimiter(routeConcurrency, routeQueue, 5*time.Second).limit(handler)
 	}
 
 	router := chi.NewRouter()
+	router.Use(apiCfg.drain.middleware)
+
+	if apiCfg.metrics != nil {
+		router.Use(middlewareMetrics(apiCfg.metrics))
+	}
+
+	if kind := os.Getenv("ANALYTICS"); kind != "" {
+		sink, err := analytics.New(kind, os.Getenv("ANALYTICS_TARGET"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		router.Use(middlewareAnalytics(sink))
+		log.Printf("Recording anonymized analytics to %s", kind)
+	}
+
+	if size := getEnvInt("REQUEST_LOG_SIZE", 0); size > 0 {
+		apiCfg.requestLog = newRequestLog(size)
+		router.Use(apiCfg.requestLog.middleware)
+	}
+
+	if chaos := chaosMiddleware(); chaos != nil {
+		router.Use(chaos)
+	}
 
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowOriginFunc:  apiCfg.allowOrigin,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"ETag", "Link", "Location", "X-Missing-Note-IDs", "X-Next-Cursor", "X-Impersonated", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
-	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		f, err := staticFiles.Open("static/index.html")
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		defer f.Close()
-		if _, err := io.Copy(w, f); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-	})
+	frontend, err := frontendFS()
+	if err != nil {
+		log.Fatal(err)
+	}
+	router.Handle("/*", staticHandler(frontend))
 
 	v1Router := chi.NewRouter()
+	v1Router.Use(middlewareEnvelope)
+	v1Router.Use(middlewareLocalizeErrors)
+	adminRouter := chi.NewRouter()
 
 	if apiCfg.DB != nil {
-		v1Router.Post("/users", apiCfg.handlerUsersCreate)
-		v1Router.Get("/users", apiCfg.middlewareAuth(apiCfg.handlerUsersGet))
-		v1Router.Get("/notes", apiCfg.middlewareAuth(apiCfg.handlerNotesGet))
-		v1Router.Post("/notes", apiCfg.middlewareAuth(apiCfg.handlerNotesCreate))
+		v1Router.Group(func(r chi.Router) {
+			r.Use(apiCfg.middlewareMaintenance)
+			r.Use(apiCfg.shedder.observe)
+			r.Post("/users", apiCfg.handlerUsersCreate)
+			r.Get("/users", apiCfg.middlewareAuth(apiCfg.handlerUsersGet))
+			r.Get("/users/storage", apiCfg.middlewareAuth(apiCfg.handlerUsersStorageGet))
+			r.Get("/users/me/usage", apiCfg.middlewareAuth(apiCfg.handlerUsageGet))
+			r.Get("/users/me/plan", apiCfg.middlewareAuth(apiCfg.handlerPlanGet))
+			r.Get("/users/me/usage-alerts", apiCfg.middlewareAuth(apiCfg.handlerUsageAlertsGet))
+			r.Post("/users/me/usage-alerts", apiCfg.middlewareAuth(apiCfg.handlerUsageAlertsCreate))
+			r.Delete("/users/me/usage-alerts/{alertID}", apiCfg.middlewareAuth(apiCfg.handlerUsageAlertsDelete))
+			r.Post("/billing/redeem", apiCfg.middlewareAuth(apiCfg.handlerBillingRedeem))
+			r.Post("/users/me/data-export", apiCfg.shedder.lowPriority(apiCfg.middlewareAuth(apiCfg.handlerAccountExportCreate)))
+			r.Get("/users/me/erasure", apiCfg.middlewareAuth(apiCfg.handlerErasureGet))
+			r.Post("/users/me/erasure", apiCfg.middlewareAuth(apiCfg.handlerErasureRequest))
+			r.Post("/users/me/erasure/confirm", apiCfg.middlewareAuth(apiCfg.handlerErasureConfirm))
+			r.Delete("/users/me/erasure", apiCfg.middlewareAuth(apiCfg.handlerErasureCancel))
+			r.Get("/notes", limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesGet)))
+			r.Post("/notes", limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesCreate)))
+			r.Delete("/notes", limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesBulkDelete)))
+			r.Get("/notes/export", apiCfg.shedder.lowPriority(limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesExport))))
+			r.Get("/notes/graph", apiCfg.shedder.lowPriority(limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesGraphGet))))
+			r.Get("/notes/slug/{slug}", apiCfg.middlewareAuth(apiCfg.handlerNotesGetBySlug))
+			r.Get("/notes/nearby", limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesNearby)))
+			r.Put("/notes/{noteID}", limitRoute(apiCfg.middlewareAuth(apiCfg.handlerNotesUpdate)))
+			r.Post("/notes/{noteID}/duplicate", apiCfg.middlewareAuth(apiCfg.handlerNotesDuplicate))
+			r.Get("/notes/{noteID}/comments", apiCfg.middlewareAuth(apiCfg.handlerCommentsGet))
+			r.Post("/notes/{noteID}/comments", apiCfg.middlewareAuth(apiCfg.handlerCommentsCreate))
+			r.Put("/notes/{noteID}/comments/{commentID}", apiCfg.middlewareAuth(apiCfg.handlerCommentsUpdate))
+			r.Delete("/notes/{noteID}/comments/{commentID}", apiCfg.middlewareAuth(apiCfg.handlerCommentsDelete))
+			r.Put("/notes/{noteID}/reactions", apiCfg.middlewareAuth(apiCfg.handlerReactionsSet))
+			r.Delete("/notes/{noteID}/reactions", apiCfg.middlewareAuth(apiCfg.handlerReactionsDelete))
+			r.Get("/notes/{noteID}/backlinks", apiCfg.middlewareAuth(apiCfg.handlerNotesBacklinksGet))
+			r.Patch("/notes/{noteID}/properties", apiCfg.middlewareAuth(apiCfg.handlerNotePropertiesPatch))
+			r.Put("/notes/{noteID}/location", apiCfg.middlewareAuth(apiCfg.handlerNoteLocationSet))
+			r.Delete("/notes/{noteID}/location", apiCfg.middlewareAuth(apiCfg.handlerNoteLocationClear))
+			r.Put("/notes/{noteID}/due", apiCfg.middlewareAuth(apiCfg.handlerNotesDueSet))
+			r.Delete("/notes/{noteID}/due", apiCfg.middlewareAuth(apiCfg.handlerNotesDueClear))
+			r.Get("/calendar.ics", apiCfg.handlerCalendarFeed)
+			r.Get("/calendar/token", apiCfg.middlewareAuth(apiCfg.handlerCalendarTokenGet))
+			r.Get("/sync", apiCfg.middlewareAuth(apiCfg.handlerSync))
+			r.Get("/templates", apiCfg.middlewareAuth(apiCfg.handlerTemplatesGet))
+			r.Post("/templates", apiCfg.middlewareAuth(apiCfg.handlerTemplatesCreate))
+			r.Post("/templates/{templateID}/notes", apiCfg.middlewareAuth(apiCfg.handlerTemplatesInstantiate))
+			r.Post("/exports", apiCfg.shedder.lowPriority(apiCfg.middlewareAuth(apiCfg.handlerExportsCreate)))
+			r.Get("/exports/{exportID}", apiCfg.middlewareAuth(apiCfg.handlerExportsGet))
+			r.Get("/exports/{exportID}/download", apiCfg.shedder.lowPriority(apiCfg.middlewareAuth(apiCfg.handlerExportsDownload)))
+			r.Get("/integrations/slack", apiCfg.middlewareAuth(apiCfg.handlerSlackIntegrationsGet))
+			r.Post("/integrations/slack", apiCfg.middlewareAuth(apiCfg.handlerSlackIntegrationsCreate))
+			r.Delete("/integrations/slack/{integrationID}", apiCfg.middlewareAuth(apiCfg.handlerSlackIntegrationsDelete))
+			if apiCfg.telegram != nil {
+				r.Post("/integrations/telegram/link-code", apiCfg.middlewareAuth(apiCfg.handlerTelegramLinkCodeCreate))
+			}
+			if apiCfg.stripe.webhookSecret != "" {
+				r.Post("/billing/stripe/webhook", apiCfg.handlerStripeWebhook)
+			}
+			if apiCfg.stripe.checkoutEnabled() {
+				r.Post("/billing/checkout", apiCfg.middlewareAuth(apiCfg.handlerBillingCheckout))
+				r.Post("/billing/portal", apiCfg.middlewareAuth(apiCfg.handlerBillingPortal))
+			}
+			r.Get("/schedules", apiCfg.middlewareAuth(apiCfg.handlerSchedulesGet))
+			r.Post("/schedules", apiCfg.middlewareAuth(apiCfg.handlerSchedulesCreate))
+			r.Delete("/schedules/{scheduleID}", apiCfg.middlewareAuth(apiCfg.handlerSchedulesDelete))
+		})
+
+		router.Route("/app", func(r chi.Router) {
+			r.Use(apiCfg.middlewareMaintenance)
+			r.Get("/", apiCfg.middlewareSession(apiCfg.handlerAppNotes))
+			r.Get("/login", apiCfg.handlerAppLoginForm)
+			r.Post("/login", apiCfg.handlerAppLogin)
+			r.Post("/logout", apiCfg.handlerAppLogout)
+			r.Post("/notes", apiCfg.middlewareSession(apiCfg.handlerAppNotesCreate))
+			r.Get("/notes/{noteID}/edit", apiCfg.middlewareSession(apiCfg.handlerAppNoteEdit))
+			r.Post("/notes/{noteID}", apiCfg.middlewareSession(apiCfg.handlerAppNoteUpdate))
+		})
+
+		if apiCfg.adminAPIKey != "" {
+			adminRouter.Get("/config", apiCfg.middlewareAdmin(apiCfg.handlerConfigGet))
+			adminRouter.Post("/config/reload", apiCfg.middlewareAdmin(apiCfg.handlerConfigReload))
+			adminRouter.Post("/drain", apiCfg.middlewareAdmin(apiCfg.handlerDrain))
+			adminRouter.Delete("/drain", apiCfg.middlewareAdmin(apiCfg.handlerUndrain))
+			adminRouter.Get("/maintenance", apiCfg.middlewareAdmin(apiCfg.handlerMaintenanceGet))
+			adminRouter.Put("/maintenance", apiCfg.middlewareAdmin(apiCfg.handlerMaintenanceUpdate))
+			adminRouter.Post("/users/{userID}/impersonate", apiCfg.middlewareAdmin(apiCfg.handlerImpersonationCreate))
+			adminRouter.Get("/users/{userID}/suspension", apiCfg.middlewareAdmin(apiCfg.handlerSuspensionGet))
+			adminRouter.Put("/users/{userID}/suspension", apiCfg.middlewareAdmin(apiCfg.handlerSuspensionCreate))
+			adminRouter.Delete("/users/{userID}/suspension", apiCfg.middlewareAdmin(apiCfg.handlerSuspensionDelete))
+			adminRouter.Put("/users/{userID}/throttle-exemption", apiCfg.middlewareAdmin(apiCfg.handlerThrottleExemptionCreate))
+			adminRouter.Delete("/users/{userID}/throttle-exemption", apiCfg.middlewareAdmin(apiCfg.handlerThrottleExemptionDelete))
+			adminRouter.Get("/legal-holds", apiCfg.middlewareAdmin(apiCfg.handlerLegalHoldsGet))
+			adminRouter.Put("/legal-holds/{subjectType}/{subjectID}", apiCfg.middlewareAdmin(apiCfg.handlerLegalHoldApply))
+			adminRouter.Delete("/legal-holds/{subjectType}/{subjectID}", apiCfg.middlewareAdmin(apiCfg.handlerLegalHoldLift))
+			adminRouter.Get("/moderation", apiCfg.middlewareAdmin(apiCfg.handlerModerationQueueGet))
+			adminRouter.Delete("/moderation/{noteID}", apiCfg.middlewareAdmin(apiCfg.handlerModerationFlagDelete))
+			adminRouter.Get("/usage", apiCfg.middlewareAdmin(apiCfg.handlerUsageRollupGet))
+			adminRouter.Get("/stats", apiCfg.middlewareAdmin(apiCfg.handlerStatsGet))
+			adminRouter.Get("/promo-codes", apiCfg.middlewareAdmin(apiCfg.handlerPromoCodesGet))
+			adminRouter.Post("/promo-codes", apiCfg.middlewareAdmin(apiCfg.handlerPromoCodesCreate))
+			if apiCfg.requestLog != nil {
+				adminRouter.Get("/requests", apiCfg.middlewareAdmin(apiCfg.handlerRequestsGet))
+			}
+		}
+	}
+
+	// Probes may use HEAD. The responses are encoded once at startup, but
+	// still go through the middleware so they get CORS headers and show up
+	// in metrics and logs.
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		v1Router.Method(method, "/healthz", handlerReadiness)
+		v1Router.Method(method, "/version", handlerVersion)
 	}
+	v1Router.Get("/readyz", apiCfg.handlerReadyz)
+	v1Router.Get("/capabilities", apiCfg.handlerCapabilities)
 
-	v1Router.Get("/healthz", handlerReadiness)
+	// With INTERNAL_PORT set, admin endpoints are only served on that port.
+	internalPort := os.Getenv("INTERNAL_PORT")
+	if internalPort == "" {
+		v1Router.Mount("/admin", adminRouter)
+	}
 
 	router.Mount("/v1", v1Router)
 	srv := &http.Server{
-		Addr:    ":" + port,
 		Handler: router,
 	}
 
-	log.Printf("Serving on port: %s\n", port)
-	log.Fatal(srv.ListenAndServe())
+	servers := []*http.Server{srv}
+	if internalPort != "" {
+		internalListener, err := net.Listen("tcp", ":"+internalPort)
+		if err != nil {
+			log.Fatal(err)
+		}
+		internalSrv := &http.Server{Handler: apiCfg.newInternalRouter(adminRouter)}
+		servers = append(servers, internalSrv)
+		go func() {
+			log.Printf("Serving internal endpoints on %s\n", internalListener.Addr())
+			if err := internalSrv.Serve(internalListener); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	shutdownDone := make(chan struct{})
+	apiCfg.drain.shutdown = shutdownServer(shutdownDone, servers...)
+	go shutdownOnSignal(apiCfg.drain.shutdown)
+
+	log.Printf("Serving on %s\n", listener.Addr())
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-shutdownDone
+	if apiCfg.DB != nil {
+		// Don't lose the counts gathered since the last periodic flush.
+		if err := apiCfg.flushUsage(context.Background()); err != nil {
+			log.Printf("Couldn't flush usage: %v", err)
+		}
+	}
+	log.Println("Server drained and stopped")
+}
+
+func logEvent(ctx context.Context, e events.Event) error {
+	log.Printf("Event %s: %s for user %s", e.Type, e.SubjectID, e.UserID)
+	return nil
+}
+
+// frontendFS returns the frontend to serve. STATIC_DIR serves it from disk
+// instead of the embedded copy, so changes show up without rebuilding.
+func frontendFS() (fs.FS, error) {
+	if dir := os.Getenv("STATIC_DIR"); dir != "" {
+		log.Printf("Serving frontend from %s", dir)
+		return os.DirFS(dir), nil
+	}
+	return fs.Sub(staticFiles, "static")
+}
+
+func getEnvInt(name string, fallback int) int {
+	n, err := parseEnvInt(name, fallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return n
+}
+
+func getEnvBool(name string, fallback bool) bool {
+	b, err := parseEnvBool(name, fallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
+
+func parseEnvInt(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, fmt.Errorf("%s must be an integer: %w", name, err)
+	}
+	return n, nil
+}
+
+func parseEnvBool(name string, fallback bool) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback, fmt.Errorf("%s must be a boolean: %w", name, err)
+	}
+	return b, nil
 }
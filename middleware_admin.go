@@ -0,0 +1,25 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/auth"
+)
+
+func (cfg *apiConfig) middlewareAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey, err := auth.GetAPIKey(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "find_api_key_failed", err)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(cfg.adminAPIKey)) != 1 {
+			respondWithError(w, http.StatusForbidden, "not_admin_api_key", nil)
+			return
+		}
+
+		handler(w, r)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/bootdotdev/learn-cicd-starter/internal/auth"
 	"github.com/bootdotdev/learn-cicd-starter/internal/database"
@@ -13,16 +14,31 @@ func (cfg *apiConfig) middlewareAuth(handler authedHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		apiKey, err := auth.GetAPIKey(r.Header)
 		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "Couldn't find api key", err)
+			respondWithError(w, http.StatusUnauthorized, "find_api_key_failed", err)
+			return
+		}
+		if strings.HasPrefix(apiKey, impersonationTokenPrefix) {
+			cfg.serveImpersonated(w, r, apiKey, handler)
 			return
 		}
 
-		user, err := cfg.DB.GetUser(r.Context(), apiKey)
+		user, _, err := cfg.staleUsers.read(apiKey, func() (database.User, error) {
+			return cfg.DB.GetUser(r.Context(), apiKey)
+		})
 		if err != nil {
-			respondWithError(w, http.StatusNotFound, "Couldn't get user", err)
+			respondWithError(w, http.StatusNotFound, "get_user_failed", err)
+			return
+		}
+		setRequestUser(r, user.ID)
+		if !cfg.allowUnsuspended(w, r, user.ID) {
+			return
+		}
+		if limit := cfg.rateLimitFor(r.Context(), user.ID); limit > 0 && !cfg.rateLimiter.allow(w, user.ID, limit) {
 			return
 		}
 
-		handler(w, r, user)
+		counter := &byteCounter{ResponseWriter: w}
+		handler(counter, r, user)
+		cfg.usage.record(user.ID, counter.bytes)
 	}
 }
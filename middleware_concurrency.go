@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// concurrencyLimiter caps the number of in-flight requests for a route.
+// Requests over the cap wait in a bounded queue; once the queue is full, or a
+// queued request has waited too long, the request is shed with a 503.
+type concurrencyLimiter struct {
+	slots   chan struct{}
+	queue   chan struct{}
+	maxWait time.Duration
+}
+
+func newConcurrencyLimiter(limit, queue int, maxWait time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		slots:   make(chan struct{}, limit),
+		queue:   make(chan struct{}, queue),
+		maxWait: maxWait,
+	}
+}
+
+func (l *concurrencyLimiter) limit(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.acquire(r) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(l.maxWait.Seconds())+1))
+			respondWithError(w, http.StatusServiceUnavailable, "too_many_concurrent_requests", nil)
+			return
+		}
+		defer func() { <-l.slots }()
+
+		handler(w, r)
+	}
+}
+
+func (l *concurrencyLimiter) acquire(r *http.Request) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return false
+	}
+	defer func() { <-l.queue }()
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
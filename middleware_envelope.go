@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// envelopeMeta maps response headers to the meta object of an envelope, so
+// handlers keep reporting side information in headers and clients that
+// prefer a body get the same thing.
+var envelopeMeta = map[string]string{
+	"X-Next-Cursor":         "next_cursor",
+	"X-Missing-Note-IDs":    "missing_ids",
+	"Retry-After":           "retry_after",
+	"X-Impersonated":        "impersonated",
+	"X-RateLimit-Limit":     "rate_limit_limit",
+	"X-RateLimit-Remaining": "rate_limit_remaining",
+	"X-RateLimit-Reset":     "rate_limit_reset",
+}
+
+// wantsEnvelope reports whether the client asked for enveloped responses
+// with a media type parameter, e.g. "Accept: application/json; envelope=1".
+func wantsEnvelope(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
+		}
+		if ok, _ := strconv.ParseBool(params["envelope"]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// middlewareEnvelope wraps JSON responses as
+// {"data": ..., "meta": {...}, "warnings": [...]} for clients that ask for
+// it, or {"error": ..., ...} for errors. Other clients get plain responses.
+// Enveloped responses are buffered, so large exports arrive all at once.
+func middlewareEnvelope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if !wantsEnvelope(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{ResponseWriter: w}
+		next.ServeHTTP(buf, r)
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		if mediaType != "application/json" {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		type envelope struct {
+			Data     json.RawMessage `json:"data,omitempty"`
+			Error    string          `json:"error,omitempty"`
+			Code     string          `json:"code,omitempty"`
+			Meta     map[string]any  `json:"meta"`
+			Warnings []string        `json:"warnings"`
+		}
+		env := envelope{Meta: map[string]any{}, Warnings: []string{}}
+		if buf.status >= 400 {
+			var errResp errorResponse
+			json.Unmarshal(buf.body.Bytes(), &errResp)
+			env.Error = errResp.Error
+			env.Code = errResp.Code
+		} else {
+			env.Data = bytes.TrimSpace(buf.body.Bytes())
+			if len(env.Data) == 0 {
+				env.Data = json.RawMessage("null")
+			}
+		}
+
+		for header, key := range envelopeMeta {
+			value := w.Header().Get(header)
+			if value == "" {
+				continue
+			}
+			if header == "X-Missing-Note-IDs" {
+				env.Meta[key] = strings.Split(value, ",")
+				continue
+			}
+			env.Meta[key] = value
+		}
+		for _, warning := range w.Header().Values("Warning") {
+			env.Warnings = append(env.Warnings, warningText(warning))
+		}
+
+		w.Header().Del("Content-Length")
+		respondWithJSON(w, buf.status, env)
+	})
+}
+
+// warningText extracts the text from a Warning header such as
+// `299 - "Unpaginated lists are deprecated"`.
+func warningText(header string) string {
+	start := strings.IndexByte(header, '"')
+	end := strings.LastIndexByte(header, '"')
+	if start < 0 || end <= start {
+		return header
+	}
+	return header[start+1 : end]
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+// localizedErrorWriter holds back JSON error responses so their message can
+// be translated. Successful responses, and errors that aren't JSON, pass
+// straight through.
+type localizedErrorWriter struct {
+	http.ResponseWriter
+	status    int
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (l *localizedErrorWriter) WriteHeader(code int) {
+	if l.status != 0 {
+		return
+	}
+	l.status = code
+	mediaType, _, _ := mime.ParseMediaType(l.Header().Get("Content-Type"))
+	if code >= 400 && mediaType == "application/json" {
+		l.buffering = true
+		return
+	}
+	l.ResponseWriter.WriteHeader(code)
+}
+
+func (l *localizedErrorWriter) Write(p []byte) (int, error) {
+	if l.status == 0 {
+		l.WriteHeader(http.StatusOK)
+	}
+	if l.buffering {
+		return l.body.Write(p)
+	}
+	return l.ResponseWriter.Write(p)
+}
+
+func (l *localizedErrorWriter) Flush() {
+	if l.buffering {
+		return
+	}
+	if flusher, ok := l.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (l *localizedErrorWriter) Unwrap() http.ResponseWriter {
+	return l.ResponseWriter
+}
+
+// middlewareLocalizeErrors translates error messages into the language the
+// client prefers in Accept-Language, by their code. Messages without a code,
+// or without a translation, stay in English.
+func middlewareLocalizeErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		if lang == i18n.DefaultLanguage {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		lw := &localizedErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+		if !lw.buffering {
+			return
+		}
+
+		var errResp errorResponse
+		if err := json.Unmarshal(lw.body.Bytes(), &errResp); err != nil || errResp.Code == "" {
+			w.WriteHeader(lw.status)
+			w.Write(lw.body.Bytes())
+			return
+		}
+		errResp.Error = i18n.Translate(lang, errResp.Code, errResp.Error)
+		w.Header().Set("Content-Language", lang)
+		w.Header().Del("Content-Length")
+		respondWithJSON(w, lw.status, errResp)
+	})
+}
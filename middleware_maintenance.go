@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (cfg *apiConfig) middlewareMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := cfg.maintenance.Load()
+		if state == nil || !state.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if state.RetryAfterSeconds > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(state.RetryAfterSeconds))
+		}
+		// The message is written by an admin, so it's sent as is under the
+		// maintenance code rather than looked up in the catalog.
+		respondWithJSON(w, http.StatusServiceUnavailable, errorResponse{
+			Error: state.Message,
+			Code:  "maintenance",
+		})
+	})
+}
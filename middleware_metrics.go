@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/statsd"
+)
+
+// metricsBackendFromEnv reads METRICS_BACKEND: statsd, or none when unset.
+func metricsBackendFromEnv() (string, error) {
+	switch backend := os.Getenv("METRICS_BACKEND"); backend {
+	case "", "none":
+		return "none", nil
+	case "statsd":
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unknown METRICS_BACKEND %q", backend)
+	}
+}
+
+// middlewareMetrics counts and times every request, tagged with the route
+// pattern, method and status class.
+func middlewareMetrics(client *statsd.Client) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			tags := []string{
+				"route:" + route,
+				"method:" + r.Method,
+				"status_class:" + strconv.Itoa(rec.status/100) + "xx",
+			}
+			client.Count("http.requests", 1, tags...)
+			client.Timing("http.request_duration", time.Since(start), tags...)
+		})
+	}
+}
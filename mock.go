@@ -0,0 +1,499 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/cors"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/auth"
+	"github.com/bootdotdev/learn-cicd-starter/internal/langdetect"
+)
+
+// mockAPIKey authenticates as the fixture user in mock mode.
+const mockAPIKey = "mock-api-key"
+
+// mockStore holds users and notes in memory for -mock. It starts from the
+// same fixtures every time, and new IDs are sequential, so runs are
+// repeatable.
+type mockStore struct {
+	mu    sync.Mutex
+	users map[string]User // by API key
+	notes []Note          // in creation order
+	seq   int
+}
+
+func newMockStore() *mockStore {
+	s := &mockStore{users: map[string]User{}}
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	user := s.addUser("Mock User", start)
+	delete(s.users, user.ApiKey)
+	user.ApiKey = mockAPIKey
+	s.users[mockAPIKey] = user
+	for i, text := range []string{
+		"Welcome to Notely! This note comes from the mock server.",
+		"Shopping list: milk, eggs, bread",
+		"See [[Shopping list]] before heading out",
+	} {
+		s.addNote(user.ID, "", text, start.Add(time.Duration(i+1)*time.Hour))
+	}
+	return s
+}
+
+func (s *mockStore) nextID() string {
+	s.seq++
+	return fmt.Sprintf("00000000-0000-7000-8000-%012d", s.seq)
+}
+
+func (s *mockStore) addUser(name string, now time.Time) User {
+	user := User{ID: s.nextID(), CreatedAt: now, UpdatedAt: now, Name: name}
+	user.ApiKey = fmt.Sprintf("mock-api-key-%d", s.seq)
+	s.users[user.ApiKey] = user
+	return user
+}
+
+func (s *mockStore) addNote(userID, title, text string, now time.Time) Note {
+	if title == "" {
+		title = deriveNoteTitle(text)
+	}
+	note := Note{
+		ID:         s.nextID(),
+		Slug:       fmt.Sprintf("mock%d", s.seq),
+		Title:      title,
+		Version:    1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Note:       text,
+		UserID:     userID,
+		Language:   langdetect.Detect(text),
+		Properties: map[string]string{},
+	}
+	s.notes = append(s.notes, note)
+	return note
+}
+
+// userNotes returns the indexes of userID's notes in s.notes.
+func (s *mockStore) userNotes(userID string) []int {
+	var idx []int
+	for i, note := range s.notes {
+		if note.UserID == userID {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// findNote returns the index of userID's note with the given ID, or slug if
+// bySlug is set, or -1.
+func (s *mockStore) findNote(userID, match string, bySlug bool) int {
+	for i, note := range s.notes {
+		key := note.ID
+		if bySlug {
+			key = note.Slug
+		}
+		if note.UserID == userID && key == match {
+			return i
+		}
+	}
+	return -1
+}
+
+// runMockServer serves the user and note endpoints from a mockStore, for
+// working on clients without a database. Every request is delayed by
+// latency, and errorPercent of them fail with a 503.
+func runMockServer(frontend fs.FS, latency time.Duration, errorPercent int) error {
+	router := newMockRouter(frontend, latency, errorPercent)
+
+	listener, err := listen()
+	if err != nil {
+		return err
+	}
+	log.Printf("Serving mock API on %s, log in with API key %q", listener.Addr(), mockAPIKey)
+	return http.Serve(listener, router)
+}
+
+// newMockRouter serves the mock API from a fresh store with fixture data.
+func newMockRouter(frontend fs.FS, latency time.Duration, errorPercent int) http.Handler {
+	s := newMockStore()
+
+	router := chi.NewRouter()
+	router.Use(cors.AllowAll().Handler)
+	router.Use(mockFaults(latency, errorPercent))
+	router.Handle("/*", staticHandler(frontend))
+	router.Route("/v1", func(r chi.Router) {
+		r.Method(http.MethodGet, "/healthz", handlerReadiness)
+		r.Method(http.MethodGet, "/version", handlerVersion)
+		r.Post("/users", s.handlerUsersCreate)
+		r.Get("/users", s.auth(s.handlerUsersGet))
+		r.Get("/notes", s.auth(s.handlerNotesGet))
+		r.Post("/notes", s.auth(s.handlerNotesCreate))
+		r.Delete("/notes", s.auth(s.handlerNotesBulkDelete))
+		r.Get("/notes/slug/{slug}", s.auth(s.handlerNotesGetBySlug))
+		r.Get("/notes/nearby", s.auth(s.handlerNotesNearby))
+		r.Put("/notes/{noteID}", s.auth(s.handlerNotesUpdate))
+		r.Patch("/notes/{noteID}/properties", s.auth(s.handlerNotePropertiesPatch))
+		r.Put("/notes/{noteID}/location", s.auth(s.handlerNoteLocationSet))
+		r.Delete("/notes/{noteID}/location", s.auth(s.handlerNoteLocationSet))
+		r.Put("/notes/{noteID}/due", s.auth(s.handlerNotesDueSet))
+		r.Delete("/notes/{noteID}/due", s.auth(s.handlerNotesDueSet))
+	})
+	return router
+}
+
+// mockFaults delays every request and fails a share of them. The random
+// sequence is seeded, so the same requests fail on every run.
+func mockFaults(latency time.Duration, errorPercent int) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(1))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(latency)
+			mu.Lock()
+			fail := rng.Intn(100) < errorPercent
+			mu.Unlock()
+			if fail && strings.HasPrefix(r.URL.Path, "/v1/") {
+				w.Header().Set("Retry-After", "1")
+				respondWithError(w, http.StatusServiceUnavailable, "injected_mock_failure", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *mockStore) auth(handler func(http.ResponseWriter, *http.Request, User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey, err := auth.GetAPIKey(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "find_api_key_failed", nil)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		user, ok := s.users[apiKey]
+		if !ok {
+			respondWithError(w, http.StatusNotFound, "get_user_failed", nil)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+func (s *mockStore) handlerUsersCreate(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", nil)
+		return
+	}
+	s.mu.Lock()
+	user := s.addUser(params.Name, time.Now().UTC().Truncate(time.Second))
+	s.mu.Unlock()
+	respondWithJSON(w, http.StatusCreated, user)
+}
+
+func (s *mockStore) handlerUsersGet(w http.ResponseWriter, r *http.Request, user User) {
+	respondWithFields(w, r, http.StatusOK, user)
+}
+
+func (s *mockStore) handlerNotesGet(w http.ResponseWriter, r *http.Request, user User) {
+	query := r.URL.Query()
+	notes := []Note{}
+	// Like the real server, the filters combine: a note is listed only if
+	// it matches all of them.
+	filters := notePropertyFilters(query)
+	matches := func(note Note) bool {
+		for name, value := range filters {
+			if v, ok := note.Properties[name]; !ok || v != value {
+				return false
+			}
+		}
+		if query.Has("title") && !strings.Contains(strings.ToLower(note.Title), strings.ToLower(query.Get("title"))) {
+			return false
+		}
+		return !query.Has("lang") || note.Language == query.Get("lang")
+	}
+	if query.Has("ids") {
+		var missing []string
+		for _, id := range strings.Split(query.Get("ids"), ",") {
+			if i := s.findNote(user.ID, id, false); i >= 0 && matches(s.notes[i]) {
+				notes = append(notes, s.notes[i])
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			w.Header().Set("X-Missing-Note-IDs", strings.Join(missing, ","))
+		}
+		respondWithFields(w, r, http.StatusOK, notes)
+		return
+	}
+	if filters != nil || query.Has("title") || query.Has("lang") {
+		// Newest first; s.notes is in the order the notes were created.
+		for _, i := range s.userNotes(user.ID) {
+			if matches(s.notes[i]) {
+				notes = append([]Note{s.notes[i]}, notes...)
+			}
+		}
+		respondWithFields(w, r, http.StatusOK, notes)
+		return
+	}
+
+	// The cursor is the ID of the last note on the previous page.
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	cursor := query.Get("cursor")
+	for _, i := range s.userNotes(user.ID) {
+		if cursor != "" {
+			if s.notes[i].ID == cursor {
+				cursor = ""
+			}
+			continue
+		}
+		notes = append(notes, s.notes[i])
+		if limit > 0 && len(notes) == limit {
+			w.Header().Set("X-Next-Cursor", s.notes[i].ID)
+			break
+		}
+	}
+	respondWithFields(w, r, http.StatusOK, notes)
+}
+
+func (s *mockStore) handlerNotesCreate(w http.ResponseWriter, r *http.Request, user User) {
+	var params struct {
+		Title string `json:"title"`
+		Note  string `json:"note"`
+	}
+	err := decodeNoteParameters(r, &params)
+	if errors.Is(err, errNoteInvalidUTF8) {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", nil)
+		return
+	}
+	text, err := normalizeNoteText(params.Note)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	title, err := normalizeNoteTitle(params.Title)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, s.addNote(user.ID, title, text, time.Now().UTC().Truncate(time.Second)))
+}
+
+func (s *mockStore) handlerNotesGetBySlug(w http.ResponseWriter, r *http.Request, user User) {
+	i := s.findNote(user.ID, chi.URLParam(r, "slug"), true)
+	if i < 0 {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	respondWithFields(w, r, http.StatusOK, s.notes[i])
+}
+
+func (s *mockStore) handlerNotesUpdate(w http.ResponseWriter, r *http.Request, user User) {
+	var params struct {
+		Title   *string `json:"title"`
+		Note    string  `json:"note"`
+		Version *int64  `json:"version"`
+	}
+	err := decodeNoteParameters(r, &params)
+	if errors.Is(err, errNoteInvalidUTF8) {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", nil)
+		return
+	}
+	text, err := normalizeNoteText(params.Note)
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	i := s.findNote(user.ID, chi.URLParam(r, "noteID"), false)
+	if i < 0 {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	note := &s.notes[i]
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseIfMatch(ifMatch, note.Version)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid_if_match_header", nil)
+			return
+		}
+		params.Version = &version
+	}
+	if params.Version == nil {
+		respondWithError(w, http.StatusPreconditionRequired, "version_required", nil)
+		return
+	}
+	if *params.Version != note.Version {
+		respondWithAPIError(w, http.StatusPreconditionFailed, errNoteVersionConflict)
+		return
+	}
+
+	// The mock doesn't remember whether a title was given, so a title that
+	// still matches the old text is taken to have been derived from it.
+	switch {
+	case params.Title != nil:
+		title, err := normalizeNoteTitle(*params.Title)
+		if err != nil {
+			respondWithAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		if title == "" {
+			title = deriveNoteTitle(text)
+		}
+		note.Title = title
+	case note.Title == deriveNoteTitle(note.Note):
+		note.Title = deriveNoteTitle(text)
+	}
+	note.Note = text
+	note.Language = langdetect.Detect(text)
+	note.Version++
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	w.Header().Set("ETag", `"`+strconv.FormatInt(note.Version, 10)+`"`)
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+// handlerNotesDueSet handles both setting (PUT) and clearing (DELETE) a due
+// date.
+func (s *mockStore) handlerNotesDueSet(w http.ResponseWriter, r *http.Request, user User) {
+	var dueAt *time.Time
+	if r.Method == http.MethodPut {
+		var params struct {
+			DueAt time.Time `json:"due_at"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.DueAt.IsZero() {
+			respondWithError(w, http.StatusBadRequest, "due_at_is_required", nil)
+			return
+		}
+		t := params.DueAt.UTC()
+		dueAt = &t
+	}
+	i := s.findNote(user.ID, chi.URLParam(r, "noteID"), false)
+	if i < 0 {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	note := &s.notes[i]
+	note.DueAt = dueAt
+	note.Version++
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+func (s *mockStore) handlerNotePropertiesPatch(w http.ResponseWriter, r *http.Request, user User) {
+	var patch map[string]*string
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", nil)
+		return
+	}
+	for name := range patch {
+		if !validPropertyName(name) {
+			respondWithErrorDetail(w, http.StatusBadRequest, "invalid_property_name", name, nil)
+			return
+		}
+	}
+	i := s.findNote(user.ID, chi.URLParam(r, "noteID"), false)
+	if i < 0 {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	note := &s.notes[i]
+	for name, value := range patch {
+		if value == nil {
+			delete(note.Properties, name)
+		} else {
+			note.Properties[name] = *value
+		}
+	}
+	note.Version++
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+// handlerNoteLocationSet handles both setting (PUT) and clearing (DELETE) a
+// location.
+func (s *mockStore) handlerNoteLocationSet(w http.ResponseWriter, r *http.Request, user User) {
+	var location *Location
+	if r.Method == http.MethodPut {
+		var params struct {
+			Latitude  *float64 `json:"latitude"`
+			Longitude *float64 `json:"longitude"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&params)
+		if err != nil || params.Latitude == nil || params.Longitude == nil || !validLocation(*params.Latitude, *params.Longitude) {
+			respondWithError(w, http.StatusBadRequest, "location_out_of_range", nil)
+			return
+		}
+		location = &Location{Latitude: *params.Latitude, Longitude: *params.Longitude}
+	}
+	i := s.findNote(user.ID, chi.URLParam(r, "noteID"), false)
+	if i < 0 {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	note := &s.notes[i]
+	note.Location = location
+	note.Version++
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+func (s *mockStore) handlerNotesNearby(w http.ResponseWriter, r *http.Request, user User) {
+	center, radius, err := parseNearbyParams(r.URL.Query())
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	notes := []Note{}
+	for _, i := range s.userNotes(user.ID) {
+		if loc := s.notes[i].Location; loc != nil && distanceMeters(center, *loc) <= radius {
+			notes = append(notes, s.notes[i])
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return distanceMeters(center, *notes[i].Location) < distanceMeters(center, *notes[j].Location)
+	})
+	respondWithFields(w, r, http.StatusOK, notes)
+}
+
+func (s *mockStore) handlerNotesBulkDelete(w http.ResponseWriter, r *http.Request, user User) {
+	query := r.URL.Query()
+	createdBefore, err := time.Parse(time.RFC3339, query.Get("created_before"))
+	if query.Get("confirm") != "true" || err != nil {
+		respondWithError(w, http.StatusBadRequest, "bulk_delete_requires_confirm_and_created_before", nil)
+		return
+	}
+	kept := s.notes[:0]
+	var deleted int64
+	for _, note := range s.notes {
+		if note.UserID == user.ID && note.CreatedAt.Before(createdBefore) {
+			deleted++
+			continue
+		}
+		kept = append(kept, note)
+	}
+	s.notes = kept
+	respondWithJSON(w, http.StatusOK, struct {
+		Deleted int64 `json:"deleted"`
+	}{deleted})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/pkg/client"
+)
+
+// newMockClient starts the mock API in process and returns a client for its
+// fixture user.
+func newMockClient(t *testing.T) *client.Client {
+	srv := httptest.NewServer(newMockRouter(fstest.MapFS{}, 0, 0))
+	t.Cleanup(srv.Close)
+	c := client.New(srv.URL, mockAPIKey)
+	c.RetryBackoff = time.Millisecond
+	return c
+}
+
+func TestClientAgainstMockServer(t *testing.T) {
+	ctx := context.Background()
+	c := newMockClient(t)
+
+	user, err := c.CreateUser(ctx, "Client Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = c.WithAPIKey(user.ApiKey)
+	if got, err := c.GetUser(ctx); err != nil || got.ID != user.ID {
+		t.Fatalf("GetUser = %+v, %v", got, err)
+	}
+
+	first, err := c.CreateTitledNote(ctx, "Groceries", "milk and eggs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Title != "Groceries" || first.Version != 1 {
+		t.Errorf("created note = %+v", first)
+	}
+	second, err := c.CreateNote(ctx, "# Plans\nsee the sea")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Title != "Plans" {
+		t.Errorf("derived title = %q, want %q", second.Title, "Plans")
+	}
+
+	var listed []client.Note
+	opts := client.ListNotesOptions{Limit: 1}
+	for {
+		notes, next, err := c.ListNotes(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, notes...)
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if len(listed) != 2 || listed[0].ID != first.ID || listed[1].ID != second.ID {
+		t.Errorf("paged through %+v, want both notes in order", listed)
+	}
+
+	notes, missing, err := c.GetNotes(ctx, []string{second.ID, "does-not-exist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].ID != second.ID || len(missing) != 1 {
+		t.Errorf("GetNotes = %+v, missing %v", notes, missing)
+	}
+
+	updated, err := c.UpdateNote(ctx, first.ID, first.Version, "milk, eggs and bread")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 2 || updated.Note != "milk, eggs and bread" {
+		t.Errorf("updated note = %+v", updated)
+	}
+	_, err = c.UpdateNote(ctx, first.ID, first.Version, "stale")
+	if !client.IsStatus(err, http.StatusPreconditionFailed) {
+		t.Errorf("stale update: err = %v, want 412", err)
+	}
+
+	project := "alpha"
+	if _, err := c.PatchNoteProperties(ctx, first.ID, map[string]*string{"project": &project}); err != nil {
+		t.Fatal(err)
+	}
+	byProject, err := c.GetNotesByProperties(ctx, map[string]string{"project": "alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byProject) != 1 || byProject[0].ID != first.ID {
+		t.Errorf("GetNotesByProperties = %+v", byProject)
+	}
+
+	home := client.Location{Latitude: 52.37, Longitude: 4.89}
+	if _, err := c.SetNoteLocation(ctx, second.ID, home); err != nil {
+		t.Fatal(err)
+	}
+	nearby, err := c.GetNotesNearby(ctx, client.Location{Latitude: 52.371, Longitude: 4.891}, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearby) != 1 || nearby[0].ID != second.ID {
+		t.Errorf("GetNotesNearby = %+v", nearby)
+	}
+
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	withDue, err := c.SetNoteDue(ctx, first.ID, due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withDue.DueAt == nil || !withDue.DueAt.Equal(due) {
+		t.Errorf("due_at = %v, want %v", withDue.DueAt, due)
+	}
+	if cleared, err := c.ClearNoteDue(ctx, first.ID); err != nil || cleared.DueAt != nil {
+		t.Errorf("ClearNoteDue = %+v, %v", cleared, err)
+	}
+
+	deleted, err := c.DeleteNotesBefore(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted %d notes, want 2", deleted)
+	}
+}
+
+func TestClientAgainstMockServerErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newMockClient(t)
+
+	_, err := c.WithAPIKey("wrong").GetUser(ctx)
+	if !client.IsStatus(err, http.StatusNotFound) {
+		t.Errorf("wrong API key: err = %v, want 404", err)
+	}
+	_, err = c.WithAPIKey("").GetUser(ctx)
+	if !client.IsStatus(err, http.StatusUnauthorized) {
+		t.Errorf("no API key: err = %v, want 401", err)
+	}
+	_, err = c.GetNoteBySlug(ctx, "no-such-slug")
+	if !client.IsStatus(err, http.StatusNotFound) {
+		t.Errorf("unknown slug: err = %v, want 404", err)
+	}
+}
+
+// TestMockMatchesServerOnMalformedBodies checks that the mock and the real
+// handlers answer a body that isn't JSON with the same status, so clients
+// tested against the mock see what production does.
+func TestMockMatchesServerOnMalformedBodies(t *testing.T) {
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		t.Errorf("unexpected query: %s", query)
+		return nil, nil, nil
+	})
+	mock := newMockRouter(fstest.MapFS{}, 0, 0)
+
+	tests := []struct {
+		name   string
+		path   string
+		server http.HandlerFunc
+	}{
+		{name: "create user", path: "/v1/users", server: cfg.handlerUsersCreate},
+		{name: "create note", path: "/v1/notes", server: func(w http.ResponseWriter, r *http.Request) {
+			cfg.handlerNotesCreate(w, r, testUser)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewRecorder()
+			tt.server(server, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("{not json")))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("{not json"))
+			req.Header.Set("Authorization", "ApiKey "+mockAPIKey)
+			mocked := httptest.NewRecorder()
+			mock.ServeHTTP(mocked, req)
+
+			if server.Code != http.StatusBadRequest || mocked.Code != server.Code {
+				t.Errorf("server answered %d and mock %d, want both %d", server.Code, mocked.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
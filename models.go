@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/bootdotdev/learn-cicd-starter/internal/database"
@@ -15,12 +17,12 @@ type User struct {
 }
 
 func databaseUserToUser(user database.User) (User, error) {
-	createdAt, err := time.Parse(time.RFC3339, user.CreatedAt)
+	createdAt, err := parseDBTime(user.CreatedAt)
 	if err != nil {
 		return User{}, err
 	}
 
-	updatedAt, err := time.Parse(time.RFC3339, user.UpdatedAt)
+	updatedAt, err := parseDBTime(user.UpdatedAt)
 	if err != nil {
 		return User{}, err
 	}
@@ -34,29 +36,70 @@ func databaseUserToUser(user database.User) (User, error) {
 }
 
 type Note struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Note      string    `json:"note"`
-	UserID    string    `json:"user_id"`
+	ID         string            `json:"id"`
+	Slug       string            `json:"slug,omitempty"`
+	Title      string            `json:"title"`
+	Version    int64             `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Note       string            `json:"note"`
+	UserID     string            `json:"user_id"`
+	DueAt      *time.Time        `json:"due_at"`
+	Language   string            `json:"language,omitempty"`
+	Properties map[string]string `json:"properties"`
+	Location   *Location         `json:"location"`
+	Reactions  map[string]int64  `json:"reactions,omitempty"`
+	// RemovedContent is only set when creating a note with sanitization on.
+	RemovedContent []string `json:"removed_content,omitempty"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 func databaseNoteToNote(post database.Note) (Note, error) {
-	createdAt, err := time.Parse(time.RFC3339, post.CreatedAt)
+	createdAt, err := parseDBTime(post.CreatedAt)
+	if err != nil {
+		return Note{}, err
+	}
+
+	updatedAt, err := parseDBTime(post.UpdatedAt)
 	if err != nil {
 		return Note{}, err
 	}
 
-	updatedAt, err := time.Parse(time.RFC3339, post.UpdatedAt)
+	properties, err := parseNoteProperties(post.Properties)
 	if err != nil {
 		return Note{}, err
 	}
+
+	var location *Location
+	if post.Latitude.Valid && post.Longitude.Valid {
+		location = &Location{Latitude: post.Latitude.Float64, Longitude: post.Longitude.Float64}
+	}
+
+	var dueAt *time.Time
+	if post.DueAt.Valid {
+		t, err := parseDBTime(post.DueAt.String)
+		if err != nil {
+			return Note{}, err
+		}
+		dueAt = &t
+	}
 	return Note{
-		ID:        post.ID,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
-		Note:      post.Note,
-		UserID:    post.UserID,
+		ID:         post.ID,
+		Slug:       post.Slug.String,
+		Title:      post.Title.String,
+		Version:    post.Version,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+		Note:       post.Note,
+		UserID:     post.UserID,
+		DueAt:      dueAt,
+		Language:   post.Language.String,
+		Properties: properties,
+		Location:   location,
 	}, nil
 }
 
@@ -72,3 +115,216 @@ func databasePostsToPosts(notes []database.Note) ([]Note, error) {
 	}
 	return result, nil
 }
+
+type Template struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	UserID    string    `json:"user_id"`
+}
+
+func databaseTemplateToTemplate(template database.Template) (Template, error) {
+	createdAt, err := parseDBTime(template.CreatedAt)
+	if err != nil {
+		return Template{}, err
+	}
+
+	updatedAt, err := parseDBTime(template.UpdatedAt)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{
+		ID:        template.ID,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		Name:      template.Name,
+		Body:      template.Body,
+		UserID:    template.UserID,
+	}, nil
+}
+
+func databaseTemplatesToTemplates(templates []database.Template) ([]Template, error) {
+	result := make([]Template, len(templates))
+	for i, template := range templates {
+		var err error
+		result[i], err = databaseTemplateToTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+type Schedule struct {
+	ID         string            `json:"id"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Spec       string            `json:"spec"`
+	Values     map[string]string `json:"values"`
+	NextRunAt  time.Time         `json:"next_run_at"`
+	TemplateID string            `json:"template_id"`
+	UserID     string            `json:"user_id"`
+}
+
+func databaseScheduleToSchedule(schedule database.Schedule) (Schedule, error) {
+	createdAt, err := parseDBTime(schedule.CreatedAt)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	updatedAt, err := parseDBTime(schedule.UpdatedAt)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	nextRunAt, err := parseDBTime(schedule.NextRunAt)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(schedule.TemplateValues), &values); err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{
+		ID:         schedule.ID,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+		Spec:       schedule.Spec,
+		Values:     values,
+		NextRunAt:  nextRunAt,
+		TemplateID: schedule.TemplateID,
+		UserID:     schedule.UserID,
+	}, nil
+}
+
+func databaseSchedulesToSchedules(schedules []database.Schedule) ([]Schedule, error) {
+	result := make([]Schedule, len(schedules))
+	for i, schedule := range schedules {
+		var err error
+		result[i], err = databaseScheduleToSchedule(schedule)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+type Comment struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	NoteID    string    `json:"note_id"`
+	UserID    string    `json:"user_id"`
+}
+
+func databaseCommentToComment(comment database.Comment) (Comment, error) {
+	createdAt, err := parseDBTime(comment.CreatedAt)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	updatedAt, err := parseDBTime(comment.UpdatedAt)
+	if err != nil {
+		return Comment{}, err
+	}
+	return Comment{
+		ID:        comment.ID,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		Body:      comment.Body,
+		NoteID:    comment.NoteID,
+		UserID:    comment.UserID,
+	}, nil
+}
+
+func databaseCommentsToComments(comments []database.Comment) ([]Comment, error) {
+	result := make([]Comment, len(comments))
+	for i, comment := range comments {
+		var err error
+		result[i], err = databaseCommentToComment(comment)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+type Export struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Kind        string    `json:"kind"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"`
+}
+
+func databaseExportToExport(export database.GetExportRow) (Export, error) {
+	createdAt, err := parseDBTime(export.CreatedAt)
+	if err != nil {
+		return Export{}, err
+	}
+
+	updatedAt, err := parseDBTime(export.UpdatedAt)
+	if err != nil {
+		return Export{}, err
+	}
+
+	result := Export{
+		ID:        export.ID,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		Kind:      export.Kind,
+		Status:    export.Status,
+		Error:     export.Error.String,
+	}
+	if export.Status == exportStatusComplete {
+		result.DownloadURL = "/v1/exports/" + export.ID + "/download"
+	}
+	return result, nil
+}
+
+type SlackIntegration struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	WebhookURL string    `json:"webhook_url"`
+	EventTypes []string  `json:"event_types"`
+	UserID     string    `json:"user_id"`
+}
+
+func databaseSlackIntegrationToSlackIntegration(integration database.SlackIntegration) (SlackIntegration, error) {
+	createdAt, err := parseDBTime(integration.CreatedAt)
+	if err != nil {
+		return SlackIntegration{}, err
+	}
+
+	updatedAt, err := parseDBTime(integration.UpdatedAt)
+	if err != nil {
+		return SlackIntegration{}, err
+	}
+	return SlackIntegration{
+		ID:         integration.ID,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+		WebhookURL: integration.WebhookUrl,
+		EventTypes: strings.Split(integration.EventTypes, ","),
+		UserID:     integration.UserID,
+	}, nil
+}
+
+func databaseSlackIntegrationsToSlackIntegrations(integrations []database.SlackIntegration) ([]SlackIntegration, error) {
+	result := make([]SlackIntegration, len(integrations))
+	for i, integration := range integrations {
+		var err error
+		result[i], err = databaseSlackIntegrationToSlackIntegration(integration)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/langdetect"
+)
+
+const languageBackfillBatch = 500
+
+// noteLanguage detects the language of a note's text, for storing with it.
+func noteLanguage(text string) sql.NullString {
+	return sql.NullString{String: langdetect.Detect(text), Valid: true}
+}
+
+// validLanguageCode reports whether lang looks like an ISO 639 code: two or
+// three lowercase letters, as stored by noteLanguage.
+func validLanguageCode(lang string) bool {
+	if len(lang) < 2 || len(lang) > 3 {
+		return false
+	}
+	for _, r := range lang {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// backfillNoteLanguages detects the language of notes created before
+// languages were detected, a batch at a time.
+func (cfg *apiConfig) backfillNoteLanguages(ctx context.Context) error {
+	notes, err := cfg.DB.GetNotesWithoutLanguage(ctx, languageBackfillBatch)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		err := cfg.DB.SetNoteLanguage(ctx, database.SetNoteLanguageParams{
+			Language: noteLanguage(note.Note),
+			ID:       note.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const (
+	earthRadiusMeters = 6_371_000
+	// metersPerDegree is the length of a degree of latitude, near enough.
+	metersPerDegree = 111_320
+
+	defaultNearbyRadiusMeters = 1_000
+	maxNearbyRadiusMeters     = 100_000
+)
+
+func validLocation(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// distanceMeters is the great-circle distance between two points, by the
+// haversine formula.
+func distanceMeters(a, b Location) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// boundingBox returns a box of latitudes and longitudes that holds every
+// point within radius meters of center. A box crossing the antimeridian is
+// split in two longitude ranges; otherwise the second range is empty.
+func boundingBox(center Location, radius float64) database.GetNotesForUserInBoxParams {
+	dLat := radius / metersPerDegree
+	box := database.GetNotesForUserInBoxParams{
+		MinLat:        math.Max(-90, center.Latitude-dLat),
+		MaxLat:        math.Min(90, center.Latitude+dLat),
+		MinLng:        -180,
+		MaxLng:        180,
+		WrappedMinLng: 1,
+		WrappedMaxLng: 0,
+	}
+	// Near a pole, the circle takes in every longitude.
+	if box.MinLat == -90 || box.MaxLat == 90 {
+		return box
+	}
+	dLng := dLat / math.Cos(center.Latitude*math.Pi/180)
+	if dLng >= 180 {
+		return box
+	}
+	box.MinLng, box.MaxLng = center.Longitude-dLng, center.Longitude+dLng
+	switch {
+	case box.MinLng < -180:
+		box.WrappedMinLng, box.WrappedMaxLng = box.MinLng+360, 180
+		box.MinLng = -180
+	case box.MaxLng > 180:
+		box.WrappedMinLng, box.WrappedMaxLng = -180, box.MaxLng-360
+		box.MaxLng = 180
+	}
+	return box
+}
+
+func (cfg *apiConfig) handlerNoteLocationSet(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	if params.Latitude == nil || params.Longitude == nil {
+		respondWithError(w, http.StatusBadRequest, "location_required", nil)
+		return
+	}
+	if !validLocation(*params.Latitude, *params.Longitude) {
+		respondWithError(w, http.StatusBadRequest, "location_out_of_range", nil)
+		return
+	}
+
+	cfg.setNoteLocation(w, r, user,
+		sql.NullFloat64{Float64: *params.Latitude, Valid: true},
+		sql.NullFloat64{Float64: *params.Longitude, Valid: true},
+	)
+}
+
+func (cfg *apiConfig) handlerNoteLocationClear(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.setNoteLocation(w, r, user, sql.NullFloat64{}, sql.NullFloat64{})
+}
+
+func (cfg *apiConfig) setNoteLocation(w http.ResponseWriter, r *http.Request, user database.User, lat, lng sql.NullFloat64) {
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.SetNoteLocation(r.Context(), database.SetNoteLocationParams{
+			Latitude:  lat,
+			Longitude: lng,
+			UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+			ID:        note.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(r.Context(), q, events.New(events.NoteUpdated, note.UserID, note.ID))
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_location_failed", err)
+		return
+	}
+
+	note, err = cfg.DB.GetNote(r.Context(), note.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+		return
+	}
+
+	noteResp, err := databaseNoteToNote(note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, noteResp)
+}
+
+// parseNearbyParams reads ?lat=, ?lng= and the optional ?radius=, in meters.
+func parseNearbyParams(query url.Values) (Location, float64, error) {
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(query.Get("lng"), 64)
+	if errLat != nil || errLng != nil {
+		return Location{}, 0, &apiError{code: "nearby_location_required"}
+	}
+	if !validLocation(lat, lng) {
+		return Location{}, 0, &apiError{code: "nearby_location_out_of_range"}
+	}
+	radius := float64(defaultNearbyRadiusMeters)
+	if query.Has("radius") {
+		var err error
+		radius, err = strconv.ParseFloat(query.Get("radius"), 64)
+		if err != nil || !(radius > 0 && radius <= maxNearbyRadiusMeters) {
+			return Location{}, 0, &apiError{code: "invalid_radius", detail: strconv.Itoa(maxNearbyRadiusMeters)}
+		}
+	}
+	return Location{Latitude: lat, Longitude: lng}, radius, nil
+}
+
+// handlerNotesNearby lists the notes within ?radius= meters of ?lat= and
+// ?lng=, nearest first. The database narrows them down to a bounding box,
+// which its index can serve, and the exact distance is checked here.
+func (cfg *apiConfig) handlerNotesNearby(w http.ResponseWriter, r *http.Request, user database.User) {
+	center, radius, err := parseNearbyParams(r.URL.Query())
+	if err != nil {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	box := boundingBox(center, radius)
+	box.UserID = user.ID
+	candidates, err := cfg.DB.GetNotesForUserInBox(r.Context(), box)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_notes_for_user_failed", err)
+		return
+	}
+
+	distances := make(map[string]float64, len(candidates))
+	notes := make([]database.Note, 0, len(candidates))
+	for _, note := range candidates {
+		d := distanceMeters(center, Location{Latitude: note.Latitude.Float64, Longitude: note.Longitude.Float64})
+		if d <= radius {
+			distances[note.ID] = d
+			notes = append(notes, note)
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return distances[notes[i].ID] < distances[notes[j].ID]
+	})
+
+	cfg.respondWithNotes(w, r, user, notes)
+}
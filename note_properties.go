@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const (
+	// maxNotePropertiesBytes bounds a note's properties, encoded as JSON.
+	maxNotePropertiesBytes = 4096
+	maxPropertyNameLength  = 64
+
+	// propertyFilterPrefix marks query parameters that filter notes by
+	// property, as in ?prop.project=alpha.
+	propertyFilterPrefix = "prop."
+)
+
+var errNotePropertiesTooLarge = &apiError{code: "properties_too_large", detail: strconv.Itoa(maxNotePropertiesBytes)}
+
+// validPropertyName reports whether name is made of letters, digits, '_'
+// and '-'. Keeping names that plain means they can be used as is in JSON
+// paths and in ?prop.name= filters.
+func validPropertyName(name string) bool {
+	if name == "" || len(name) > maxPropertyNameLength {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func parseNoteProperties(properties string) (map[string]string, error) {
+	parsed := map[string]string{}
+	if err := json.Unmarshal([]byte(properties), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// handlerNotePropertiesPatch merges the body into a note's properties, like
+// a JSON merge patch: each name is set to its value, or removed if the value
+// is null. Values are strings.
+func (cfg *apiConfig) handlerNotePropertiesPatch(w http.ResponseWriter, r *http.Request, user database.User) {
+	patch := map[string]*string{}
+	err := decodeNoteParameters(r, &patch)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, errNoteInvalidUTF8):
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	case errors.As(err, &typeErr):
+		respondWithError(w, http.StatusBadRequest, "property_values_not_strings", nil)
+		return
+	case err != nil:
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	for name := range patch {
+		if !validPropertyName(name) {
+			respondWithErrorDetail(w, http.StatusBadRequest, "invalid_property_name", name, nil)
+			return
+		}
+	}
+
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		current, err := q.GetNote(r.Context(), note.ID)
+		if err != nil {
+			return err
+		}
+		properties, err := parseNoteProperties(current.Properties)
+		if err != nil {
+			return err
+		}
+		for name, value := range patch {
+			if value == nil {
+				delete(properties, name)
+			} else {
+				properties[name] = *value
+			}
+		}
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			return err
+		}
+		if len(encoded) > maxNotePropertiesBytes {
+			return errNotePropertiesTooLarge
+		}
+
+		err = q.SetNoteProperties(r.Context(), database.SetNotePropertiesParams{
+			Properties: string(encoded),
+			UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
+			ID:         note.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(r.Context(), q, events.New(events.NoteUpdated, note.UserID, note.ID))
+	})
+	if errors.Is(err, errNotePropertiesTooLarge) {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_properties_failed", err)
+		return
+	}
+
+	note, err = cfg.DB.GetNote(r.Context(), note.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+		return
+	}
+	noteResp, err := databaseNoteToNote(note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+		return
+	}
+	w.Header().Set("ETag", noteETag(note))
+	respondWithJSON(w, http.StatusOK, noteResp)
+}
+
+// notePropertyFilters returns the ?prop.name=value filters in query, by
+// name.
+func notePropertyFilters(query url.Values) map[string]string {
+	var filters map[string]string
+	for key := range query {
+		name, ok := strings.CutPrefix(key, propertyFilterPrefix)
+		if !ok {
+			continue
+		}
+		if filters == nil {
+			filters = map[string]string{}
+		}
+		filters[name] = query.Get(key)
+	}
+	return filters
+}
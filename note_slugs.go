@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/ids"
+)
+
+const (
+	// With 64 random bits a collision is already unlikely; retrying a few
+	// times makes a failure practically impossible.
+	slugAttempts      = 5
+	slugBackfillBatch = 500
+)
+
+// newNoteSlug returns a slug that no note has yet.
+func newNoteSlug(ctx context.Context, q *database.Queries) (sql.NullString, error) {
+	for i := 0; i < slugAttempts; i++ {
+		slug := sql.NullString{String: ids.Slug(), Valid: true}
+		_, err := q.GetNoteBySlug(ctx, slug)
+		if errors.Is(err, sql.ErrNoRows) {
+			return slug, nil
+		}
+		if err != nil {
+			return sql.NullString{}, err
+		}
+	}
+	return sql.NullString{}, fmt.Errorf("no unused slug after %d attempts", slugAttempts)
+}
+
+func (cfg *apiConfig) handlerNotesGetBySlug(w http.ResponseWriter, r *http.Request, user database.User) {
+	note, err := cfg.DB.GetNoteBySlug(r.Context(), sql.NullString{String: chi.URLParam(r, "slug"), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && note.UserID != user.ID) {
+		respondWithError(w, http.StatusNotFound, "note_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_note_failed", err)
+		return
+	}
+
+	noteResp, err := databaseNoteToNote(note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+		return
+	}
+	w.Header().Set("ETag", noteETag(note))
+	respondWithFields(w, r, http.StatusOK, noteResp)
+}
+
+// backfillNoteSlugs gives slugs to notes created before slugs existed, a
+// batch at a time.
+func (cfg *apiConfig) backfillNoteSlugs(ctx context.Context) error {
+	noteIDs, err := cfg.DB.GetNotesWithoutSlug(ctx, slugBackfillBatch)
+	if err != nil {
+		return err
+	}
+	for _, id := range noteIDs {
+		slug, err := newNoteSlug(ctx, cfg.DB)
+		if err != nil {
+			return err
+		}
+		err = cfg.DB.SetNoteSlug(ctx, database.SetNoteSlugParams{Slug: slug, ID: id})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+// maxNoteLength is the most characters a note can hold. Characters are
+// counted as code points after NFC normalization, so an accented letter
+// counts once however the client encoded it, and emoji count the same as
+// letters rather than by their size in UTF-8.
+const maxNoteLength = 10_000
+
+// noteTextError is returned by createNote and updateNote for text that
+// can't be stored as a note.
+type noteTextError struct {
+	code   string
+	detail string
+}
+
+func (e *noteTextError) Error() string {
+	return i18n.Message(e.code) + e.detail
+}
+
+func (e *noteTextError) errorCode() (code, detail string) {
+	return e.code, e.detail
+}
+
+var errNoteInvalidUTF8 = &noteTextError{code: "note_invalid_utf8"}
+
+// normalizeNoteText checks that text is valid UTF-8 and no longer than
+// maxNoteLength, and returns it in NFC, so that notes that look the same are
+// stored, searched and counted the same.
+func normalizeNoteText(text string) (string, error) {
+	if !utf8.ValidString(text) {
+		return "", errNoteInvalidUTF8
+	}
+	text = norm.NFC.String(text)
+	if utf8.RuneCountInString(text) > maxNoteLength {
+		return "", &noteTextError{code: "note_too_long", detail: strconv.Itoa(maxNoteLength)}
+	}
+	return text, nil
+}
+
+// decodeNoteParameters decodes a JSON request body into params. It rejects
+// bodies that aren't valid UTF-8 with errNoteInvalidUTF8, since
+// encoding/json would otherwise quietly replace the bad bytes.
+func decodeNoteParameters(r *http.Request, params any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(body) {
+		return errNoteInvalidUTF8
+	}
+	return json.Unmarshal(body, params)
+}
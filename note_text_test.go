@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzDecodeNoteParameters(f *testing.F) {
+	for _, seed := range []string{
+		`{"note":"hello","title":"hi"}`,
+		`{"note":""}`,
+		`{"note":null}`,
+		`{"note":123}`,
+		`{"note":"café"}`,
+		`{"note":"` + "\xff\xfe" + `"}`,
+		`[]`,
+		`{`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		type parameters struct {
+			Note  string `json:"note"`
+			Title string `json:"title"`
+		}
+		r := httptest.NewRequest("POST", "/v1/notes", strings.NewReader(string(body)))
+		params := parameters{}
+		err := decodeNoteParameters(r, &params)
+		if !utf8.Valid(body) && !errors.Is(err, errNoteInvalidUTF8) {
+			t.Fatalf("invalid UTF-8 body %q decoded with error %v", body, err)
+		}
+		if err != nil {
+			return
+		}
+
+		text, err := normalizeNoteText(params.Note)
+		if err != nil {
+			return
+		}
+		if !utf8.ValidString(text) || utf8.RuneCountInString(text) > maxNoteLength {
+			t.Errorf("normalizeNoteText(%q) = %q, which isn't a storable note", params.Note, text)
+		}
+		if again, _ := normalizeNoteText(text); again != text {
+			t.Errorf("normalizeNoteText isn't idempotent on %q", params.Note)
+		}
+	})
+}
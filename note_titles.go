@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/sanitize"
+)
+
+const (
+	// derivedTitleLength is how many characters of a note's first line make
+	// its title when it isn't given one.
+	derivedTitleLength = 70
+	maxNoteTitleLength = 200
+	titleBackfillBatch = 500
+)
+
+// deriveNoteTitle makes a title from the first line of text that isn't
+// blank, without any Markdown heading marks, cut to derivedTitleLength.
+func deriveNoteTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "#")), " ")
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > derivedTitleLength {
+			line = strings.TrimSpace(string(runes[:derivedTitleLength-1])) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// normalizeNoteTitle checks a title given by the client and returns it in
+// NFC on a single line, with runs of whitespace collapsed.
+func normalizeNoteTitle(title string) (string, error) {
+	if !utf8.ValidString(title) {
+		return "", errNoteInvalidUTF8
+	}
+	title = strings.Join(strings.Fields(norm.NFC.String(title)), " ")
+	if utf8.RuneCountInString(title) > maxNoteTitleLength {
+		return "", &noteTextError{code: "title_too_long", detail: strconv.Itoa(maxNoteTitleLength)}
+	}
+	return title, nil
+}
+
+// storedNoteTitle returns the title to store for a note and whether it was
+// given rather than derived. An empty title means derive one from text.
+func storedNoteTitle(title, text string) (sql.NullString, bool) {
+	if title != "" {
+		return sql.NullString{String: title, Valid: true}, true
+	}
+	return sql.NullString{String: deriveNoteTitle(text), Valid: true}, false
+}
+
+// sanitizeNote strips active content from a note's title and text, and
+// returns what was removed from either.
+func sanitizeNote(title, text string) (string, string, []string) {
+	text, removed := sanitize.HTML(text)
+	title, fromTitle := sanitize.HTML(title)
+	for _, what := range fromTitle {
+		if !slices.Contains(removed, what) {
+			removed = append(removed, what)
+		}
+	}
+	return strings.TrimSpace(title), text, removed
+}
+
+// likePattern matches s anywhere in a LIKE ... ESCAPE '\' comparison.
+func likePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(s) + "%"
+}
+
+// backfillNoteTitles derives titles for notes created before notes had
+// titles, a batch at a time.
+func (cfg *apiConfig) backfillNoteTitles(ctx context.Context) error {
+	notes, err := cfg.DB.GetNotesWithoutTitle(ctx, titleBackfillBatch)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		err := cfg.DB.SetNoteTitle(ctx, database.SetNoteTitleParams{
+			Title: sql.NullString{String: deriveNoteTitle(note.Note), Valid: true},
+			ID:    note.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
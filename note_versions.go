@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+// errNoteVersionConflict means a note was changed since the caller read it.
+var errNoteVersionConflict = &apiError{code: "note_version_conflict"}
+
+// noteETag is the entity tag for a note: its version, which is bumped in the
+// same UPDATE as every change to the note.
+func noteETag(note database.Note) string {
+	return `"` + strconv.FormatInt(note.Version, 10) + `"`
+}
+
+// parseIfMatch returns the version named by an If-Match header such as "3".
+// A "*" matches whatever version is stored, so it returns current.
+func parseIfMatch(header string, current int64) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return current, nil
+	}
+	unquoted, ok := strings.CutPrefix(header, `"`)
+	unquoted, ok2 := strings.CutSuffix(unquoted, `"`)
+	if !ok || !ok2 {
+		return 0, errors.New("If-Match must be a single quoted version, e.g. \"3\"")
+	}
+	return strconv.ParseInt(unquoted, 10, 64)
+}
+
+// handlerNotesUpdate replaces the text of a note. The caller must say which
+// version they edited, in an If-Match header or a version field, so that two
+// clients can't silently overwrite each other's changes.
+func (cfg *apiConfig) handlerNotesUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Title   *string `json:"title"`
+		Note    string  `json:"note"`
+		Version *int64  `json:"version"`
+	}
+	params := parameters{}
+	err := decodeNoteParameters(r, &params)
+	if errors.Is(err, errNoteInvalidUTF8) {
+		respondWithAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+
+	note, ok := cfg.getNoteForUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	switch {
+	case r.Header.Get("If-Match") != "":
+		version, err := parseIfMatch(r.Header.Get("If-Match"), note.Version)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid_if_match_header", err)
+			return
+		}
+		if params.Version != nil && *params.Version != version {
+			respondWithError(w, http.StatusBadRequest, "version_mismatch", nil)
+			return
+		}
+		params.Version = &version
+	case params.Version == nil:
+		respondWithError(w, http.StatusPreconditionRequired, "version_required", nil)
+		return
+	}
+
+	if *params.Version != note.Version {
+		w.Header().Set("ETag", noteETag(note))
+		respondWithAPIError(w, http.StatusPreconditionFailed, errNoteVersionConflict)
+		return
+	}
+	note.Version = *params.Version
+
+	updated, removed, err := cfg.updateNote(r.Context(), note, params.Title, params.Note)
+	var invalid *noteTextError
+	if errors.As(err, &invalid) {
+		respondWithAPIError(w, http.StatusBadRequest, invalid)
+		return
+	}
+	var rejected *noteRejectedError
+	if errors.As(err, &rejected) {
+		respondWithAPIError(w, http.StatusUnprocessableEntity, rejected)
+		return
+	}
+	if errors.Is(err, errNoteVersionConflict) {
+		respondWithAPIError(w, http.StatusPreconditionFailed, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "update_note_failed", err)
+		return
+	}
+
+	noteResp, err := databaseNoteToNote(updated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_note_failed", err)
+		return
+	}
+	noteResp.RemovedContent = removed
+
+	w.Header().Set("ETag", noteETag(updated))
+	respondWithJSON(w, http.StatusOK, noteResp)
+}
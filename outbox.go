@@ -0,0 +1,101 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const (
+	outboxBatchSize = 100
+	outboxRetention = 7 * 24 * time.Hour
+	// outboxLease is how long a dispatcher has to deliver the events it
+	// claimed. Events it fails to deliver, or that are still claimed when
+	// it dies, are picked up again once their lease runs out.
+	outboxLease = time.Minute
+	// outboxMaxAttempts bounds retries, so an event that can never be
+	// delivered, like one for a deleted Slack webhook, is eventually
+	// dropped.
+	outboxMaxAttempts = 30
+)
+
+// withTx runs fn against a transaction, committing if fn succeeds.
+func (cfg *apiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := beginTx(ctx, cfg.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(database.New(cfg.wrapDB(tx))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// enqueueEvent writes e to the outbox. Call it in the same transaction as the
+// change the event describes, so the event is stored if and only if the change
+// is; dispatchOutbox delivers it afterwards.
+func enqueueEvent(ctx context.Context, q *database.Queries, e events.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return q.CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		CreatedAt: e.OccurredAt.Format(time.RFC3339),
+		Payload:   string(payload),
+	})
+}
+
+// dispatchOutbox claims a batch of pending outbox events and publishes them
+// on the event bus in the order they were written, marking each one sent
+// once every handler has delivered it. Claims are leases, so several
+// instances can dispatch at once without publishing the same event twice.
+// Delivery is at least once: an event whose handlers fail, or whose
+// dispatcher dies before marking it, is published again after its lease.
+// A retried event can arrive after events written later.
+func (cfg *apiConfig) dispatchOutbox(ctx context.Context) error {
+	now := time.Now().UTC()
+	rows, err := cfg.DB.ClaimOutboxEvents(ctx, database.ClaimOutboxEventsParams{
+		ClaimedUntil: now.Add(outboxLease).Format(time.RFC3339),
+		Now:          now.Format(time.RFC3339),
+		Limit:        outboxBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+	// RETURNING doesn't promise any order.
+	slices.SortFunc(rows, func(a, b database.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+	for _, row := range rows {
+		e := events.Event{}
+		if err := json.Unmarshal([]byte(row.Payload), &e); err != nil {
+			// Skip it rather than retry it forever.
+			log.Printf("Dropping malformed outbox event %d: %v", row.ID, err)
+		} else if err := cfg.events.Publish(ctx, e); err != nil {
+			if row.Attempts < outboxMaxAttempts {
+				log.Printf("Couldn't deliver outbox event %d, retrying after %s: %v", row.ID, outboxLease, err)
+				continue
+			}
+			log.Printf("Dropping outbox event %d after %d attempts: %v", row.ID, row.Attempts, err)
+		}
+
+		err := cfg.DB.MarkOutboxEventSent(ctx, database.MarkOutboxEventSentParams{
+			SentAt: sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true},
+			ID:     row.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cutoff := time.Now().UTC().Add(-outboxRetention).Format(time.RFC3339)
+	return cfg.DB.DeleteSentOutboxEvents(ctx, sql.NullString{String: cutoff, Valid: true})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+func TestDispatchOutboxMarksOnlyDeliveredEvents(t *testing.T) {
+	payload := func(subjectID string) string {
+		dat, err := json.Marshal(events.New(events.NoteCreated, testUser.ID, subjectID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(dat)
+	}
+	// Returned out of order, as RETURNING may.
+	claimed := [][]driver.Value{
+		{int64(3), "2024-07-01T12:00:00Z", payload("undeliverable"), nil, "2024-07-01T12:01:00Z", int64(outboxMaxAttempts)},
+		{int64(1), "2024-07-01T12:00:00Z", payload("delivered"), nil, "2024-07-01T12:01:00Z", int64(1)},
+		{int64(2), "2024-07-01T12:00:00Z", payload("failing"), nil, "2024-07-01T12:01:00Z", int64(1)},
+		{int64(4), "2024-07-01T12:00:00Z", "not json", nil, "2024-07-01T12:01:00Z", int64(1)},
+	}
+	var marked []int64
+	cfg := newTestAPIConfig(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "SET claimed_until"):
+			return []string{"id", "created_at", "payload", "sent_at", "claimed_until", "attempts"}, claimed, nil
+		case strings.Contains(query, "SET sent_at"):
+			marked = append(marked, args[1].(int64))
+		}
+		return nil, nil, nil
+	})
+
+	var published []string
+	cfg.events = events.NewBus()
+	cfg.events.SubscribeAll(func(_ context.Context, e events.Event) error {
+		published = append(published, e.SubjectID)
+		if e.SubjectID != "delivered" {
+			return errors.New("broker is down")
+		}
+		return nil
+	})
+
+	if err := cfg.dispatchOutbox(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"delivered", "failing", "undeliverable"}; !slices.Equal(published, want) {
+		t.Errorf("published %v, want %v in outbox order", published, want)
+	}
+	// 2 failed and is left for a retry. 3 failed on its last attempt and 4
+	// can't be decoded, so both are given up on.
+	if want := []int64{1, 3, 4}; !slices.Equal(marked, want) {
+		t.Errorf("marked %v sent, want %v", marked, want)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+var errInvalidCursor = &apiError{code: "invalid_cursor"}
+
+// noteCursor marks the position of the last note on a page. Pages are ordered
+// by (created_at, id), so the next page starts strictly after it.
+type noteCursor struct {
+	CreatedAt string
+	ID        string
+}
+
+func (c noteCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt + "|" + c.ID))
+}
+
+func decodeNoteCursor(s string) (noteCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return noteCursor{}, errInvalidCursor
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found || createdAt == "" || id == "" {
+		return noteCursor{}, errInvalidCursor
+	}
+	return noteCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+type pageParams struct {
+	Limit  int
+	Cursor noteCursor
+}
+
+// parsePageParams reads limit and cursor from the query string. ok is false
+// when neither is present, meaning the client didn't ask for pagination.
+func parsePageParams(query url.Values) (params pageParams, ok bool, err error) {
+	limitStr := query.Get("limit")
+	cursorStr := query.Get("cursor")
+	if limitStr == "" && cursorStr == "" {
+		return pageParams{}, false, nil
+	}
+
+	params.Limit = defaultPageSize
+	if limitStr != "" {
+		params.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || params.Limit < 1 || params.Limit > maxPageSize {
+			return pageParams{}, false, &apiError{code: "invalid_page_limit", detail: strconv.Itoa(maxPageSize)}
+		}
+	}
+	if cursorStr != "" {
+		params.Cursor, err = decodeNoteCursor(cursorStr)
+		if err != nil {
+			return pageParams{}, false, err
+		}
+	}
+	return params, true, nil
+}
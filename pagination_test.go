@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+func FuzzDecodeNoteCursor(f *testing.F) {
+	f.Add(noteCursor{CreatedAt: "2024-01-02T03:04:05Z", ID: "abc"}.encode())
+	f.Add("")
+	f.Add("fA")
+	f.Add("!!!")
+	f.Add(noteCursor{CreatedAt: "a|b", ID: "c"}.encode())
+	f.Fuzz(func(t *testing.T, s string) {
+		cursor, err := decodeNoteCursor(s)
+		if err != nil {
+			return
+		}
+		if cursor.CreatedAt == "" || cursor.ID == "" {
+			t.Errorf("decodeNoteCursor(%q) = %+v, with an empty field", s, cursor)
+		}
+	})
+}
+
+func FuzzParsePageParams(f *testing.F) {
+	f.Add("limit=10")
+	f.Add("limit=0")
+	f.Add("limit=-1&cursor=x")
+	f.Add("cursor=" + noteCursor{CreatedAt: "2024-01-02T03:04:05Z", ID: "abc"}.encode())
+	f.Add("limit=99999999999999999999")
+	f.Add("%zz")
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return
+		}
+		params, ok, err := parsePageParams(query)
+		if err != nil || !ok {
+			return
+		}
+		if params.Limit < 1 || params.Limit > maxPageSize {
+			t.Errorf("parsePageParams(%q) allowed limit %d", rawQuery, params.Limit)
+		}
+	})
+}
+
+const (
+	benchUserID = "benchmark-pagination-user"
+	benchNotes  = 100_000
+)
+
+// openBenchDB opens the database in BENCH_DATABASE_URL, which must have the
+// migrations applied, and makes sure the benchmark user has benchNotes
+// notes. Benchmarks are skipped without it, since the point is to measure a
+// real database.
+func openBenchDB(b *testing.B) *sql.DB {
+	dbURL := os.Getenv("BENCH_DATABASE_URL")
+	if dbURL == "" {
+		b.Skip("BENCH_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("libsql", dbURL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err = db.ExecContext(ctx, "INSERT OR IGNORE INTO users (id, created_at, updated_at, name, api_key) VALUES (?, ?, ?, ?, ?)",
+		benchUserID, now, now, "pagination benchmark", benchUserID)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var have int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM notes WHERE user_id = ?", benchUserID).Scan(&have); err != nil {
+		b.Fatal(err)
+	}
+
+	const batch = 500
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := have; i < benchNotes; i += batch {
+		var query strings.Builder
+		query.WriteString("INSERT INTO notes (id, created_at, updated_at, note, user_id) VALUES ")
+		var args []any
+		for j := i; j < min(i+batch, benchNotes); j++ {
+			if j > i {
+				query.WriteString(", ")
+			}
+			query.WriteString("(?, ?, ?, ?, ?)")
+			// Notes share timestamps in pairs so the id tiebreak is exercised.
+			createdAt := start.Add(time.Duration(j/2) * time.Second).Format(time.RFC3339)
+			args = append(args, fmt.Sprintf("%s-%06d", benchUserID, j), createdAt, createdAt, "benchmark note", benchUserID)
+		}
+		if _, err := db.ExecContext(ctx, query.String(), args...); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return db
+}
+
+// BenchmarkNotesPage compares reading a page deep into a large account with
+// keyset pagination, as GET /v1/notes does, against LIMIT/OFFSET.
+func BenchmarkNotesPage(b *testing.B) {
+	db := openBenchDB(b)
+	q := database.New(db)
+	ctx := context.Background()
+
+	for _, page := range []int{1, 100, 1000} {
+		skip := (page - 1) * defaultPageSize
+
+		b.Run(fmt.Sprintf("keyset/page=%d", page), func(b *testing.B) {
+			// The cursor a client would hold after reading the previous page.
+			cursor := noteCursor{}
+			if skip > 0 {
+				err := db.QueryRowContext(ctx,
+					"SELECT created_at, id FROM notes WHERE user_id = ? ORDER BY created_at, id LIMIT 1 OFFSET ?",
+					benchUserID, skip-1,
+				).Scan(&cursor.CreatedAt, &cursor.ID)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				notes, err := q.GetNotesForUserAfter(ctx, database.GetNotesForUserAfterParams{
+					UserID:      benchUserID,
+					CreatedAt:   cursor.CreatedAt,
+					CreatedAt_2: cursor.CreatedAt,
+					ID:          cursor.ID,
+					Limit:       defaultPageSize,
+				})
+				if err != nil || len(notes) != defaultPageSize {
+					b.Fatalf("got %d notes: %v", len(notes), err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("offset/page=%d", page), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rows, err := db.QueryContext(ctx,
+					"SELECT * FROM notes WHERE user_id = ? ORDER BY created_at, id LIMIT ? OFFSET ?",
+					benchUserID, defaultPageSize, skip,
+				)
+				if err != nil {
+					b.Fatal(err)
+				}
+				columns, err := rows.Columns()
+				if err != nil {
+					b.Fatal(err)
+				}
+				values := make([]any, len(columns))
+				for j := range values {
+					values[j] = new(any)
+				}
+				n := 0
+				for rows.Next() {
+					if err := rows.Scan(values...); err != nil {
+						b.Fatal(err)
+					}
+					n++
+				}
+				rows.Close()
+				if err := rows.Err(); err != nil || n != defaultPageSize {
+					b.Fatalf("got %d notes: %v", n, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Package client is a Go client for the Notely API.
+//
+//	c := client.New("https://notely.example.com", apiKey)
+//	note, err := c.CreateNote(ctx, "Buy milk")
+//
+// Requests that fail with a network error, a 429 or a 502/503/504 are
+// retried with exponential backoff, honouring Retry-After. POST requests
+// are only retried on a 429 or 503, which the server sends before doing any
+// work, so retries never create duplicates.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API as the user owning its API key.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+
+	// MaxRetries is how many times a failed request is retried. Zero
+	// disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry; it doubles with each
+	// attempt.
+	RetryBackoff time.Duration
+}
+
+// New returns a client for the instance at baseURL. apiKey may be empty for
+// calls that don't need authentication, such as CreateUser.
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		apiKey:       apiKey,
+		http:         &http.Client{Timeout: 30 * time.Second},
+		MaxRetries:   3,
+		RetryBackoff: 200 * time.Millisecond,
+	}
+}
+
+// WithAPIKey returns a copy of c that authenticates with apiKey.
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	copied := *c
+	copied.apiKey = apiKey
+	return &copied
+}
+
+// APIError is returned for responses with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Code identifies the error independently of Message, which may be
+	// localized. It's empty when the response wasn't a JSON error, such
+	// as one from a proxy in front of the server.
+	Code string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("notely: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsStatus reports whether err is an *APIError with the given status code.
+func IsStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+type User struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	ApiKey    string    `json:"api_key"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Note struct {
+	ID         string            `json:"id"`
+	Slug       string            `json:"slug,omitempty"`
+	Title      string            `json:"title"`
+	Version    int64             `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Note       string            `json:"note"`
+	UserID     string            `json:"user_id"`
+	DueAt      *time.Time        `json:"due_at"`
+	Language   string            `json:"language,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Location   *Location         `json:"location,omitempty"`
+	Reactions  map[string]int64  `json:"reactions,omitempty"`
+	// RemovedContent is only set when creating or updating a note with
+	// sanitization on.
+	RemovedContent []string `json:"removed_content,omitempty"`
+}
+
+// CreateUser creates a user. Use the returned ApiKey with WithAPIKey to
+// act as them.
+func (c *Client) CreateUser(ctx context.Context, name string) (User, error) {
+	var user User
+	_, err := c.do(ctx, http.MethodPost, "/v1/users", nil, map[string]string{"name": name}, &user)
+	return user, err
+}
+
+// GetUser returns the user owning the client's API key.
+func (c *Client) GetUser(ctx context.Context) (User, error) {
+	var user User
+	_, err := c.do(ctx, http.MethodGet, "/v1/users", nil, nil, &user)
+	return user, err
+}
+
+func (c *Client) CreateNote(ctx context.Context, text string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodPost, "/v1/notes", nil, map[string]string{"note": text}, &note)
+	return note, err
+}
+
+// CreateTitledNote is CreateNote with a title. Notes created without one
+// are titled after their first line.
+func (c *Client) CreateTitledNote(ctx context.Context, title, text string) (Note, error) {
+	var note Note
+	body := map[string]string{"title": title, "note": text}
+	_, err := c.do(ctx, http.MethodPost, "/v1/notes", nil, body, &note)
+	return note, err
+}
+
+// ListNotesOptions selects one page of notes. With both fields zero, all
+// notes are returned at once.
+type ListNotesOptions struct {
+	Limit  int
+	Cursor string
+}
+
+// ListNotes returns a page of notes, oldest first, and the cursor for the
+// next page, which is empty after the last page.
+func (c *Client) ListNotes(ctx context.Context, opts ListNotesOptions) ([]Note, string, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	var notes []Note
+	header, err := c.do(ctx, http.MethodGet, "/v1/notes", query, nil, &notes)
+	if err != nil {
+		return nil, "", err
+	}
+	return notes, header.Get("X-Next-Cursor"), nil
+}
+
+// GetNotes fetches up to 100 notes by ID, in the order given. It also
+// returns the IDs that don't exist or belong to someone else.
+func (c *Client) GetNotes(ctx context.Context, ids []string) ([]Note, []string, error) {
+	var notes []Note
+	header, err := c.do(ctx, http.MethodGet, "/v1/notes", url.Values{"ids": {strings.Join(ids, ",")}}, nil, &notes)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	if m := header.Get("X-Missing-Note-IDs"); m != "" {
+		missing = strings.Split(m, ",")
+	}
+	return notes, missing, nil
+}
+
+// GetNotesByLanguage lists the notes detected as written in lang, an ISO
+// 639-1 code. "und" lists the notes whose language couldn't be detected.
+func (c *Client) GetNotesByLanguage(ctx context.Context, lang string) ([]Note, error) {
+	var notes []Note
+	_, err := c.do(ctx, http.MethodGet, "/v1/notes", url.Values{"lang": {lang}}, nil, &notes)
+	return notes, err
+}
+
+// SearchNotesByTitle lists the notes whose title contains query, ignoring
+// case, newest first.
+func (c *Client) SearchNotesByTitle(ctx context.Context, query string) ([]Note, error) {
+	var notes []Note
+	_, err := c.do(ctx, http.MethodGet, "/v1/notes", url.Values{"title": {query}}, nil, &notes)
+	return notes, err
+}
+
+func (c *Client) GetNoteBySlug(ctx context.Context, slug string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodGet, "/v1/notes/slug/"+url.PathEscape(slug), nil, nil, &note)
+	return note, err
+}
+
+// UpdateNote replaces the text of a note, provided it is still at version.
+// If someone else changed it first, the error satisfies
+// IsStatus(err, http.StatusPreconditionFailed).
+func (c *Client) UpdateNote(ctx context.Context, id string, version int64, text string) (Note, error) {
+	var note Note
+	body := map[string]any{"note": text, "version": version}
+	_, err := c.do(ctx, http.MethodPut, "/v1/notes/"+url.PathEscape(id), nil, body, &note)
+	return note, err
+}
+
+// PatchNoteProperties sets the named properties of a note, removing those
+// whose value is nil, and leaves the rest as they are.
+func (c *Client) PatchNoteProperties(ctx context.Context, id string, patch map[string]*string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodPatch, "/v1/notes/"+url.PathEscape(id)+"/properties", nil, patch, &note)
+	return note, err
+}
+
+// GetNotesByProperties lists the notes that have all of the given property
+// values.
+func (c *Client) GetNotesByProperties(ctx context.Context, properties map[string]string) ([]Note, error) {
+	query := url.Values{}
+	for name, value := range properties {
+		query.Set("prop."+name, value)
+	}
+	var notes []Note
+	_, err := c.do(ctx, http.MethodGet, "/v1/notes", query, nil, &notes)
+	return notes, err
+}
+
+func (c *Client) SetNoteLocation(ctx context.Context, id string, location Location) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodPut, "/v1/notes/"+url.PathEscape(id)+"/location", nil, location, &note)
+	return note, err
+}
+
+func (c *Client) ClearNoteLocation(ctx context.Context, id string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodDelete, "/v1/notes/"+url.PathEscape(id)+"/location", nil, nil, &note)
+	return note, err
+}
+
+// GetNotesNearby lists the notes within radiusMeters of center, nearest
+// first. A radius of 0 uses the server's default of 1000 meters.
+func (c *Client) GetNotesNearby(ctx context.Context, center Location, radiusMeters float64) ([]Note, error) {
+	query := url.Values{
+		"lat": {strconv.FormatFloat(center.Latitude, 'f', -1, 64)},
+		"lng": {strconv.FormatFloat(center.Longitude, 'f', -1, 64)},
+	}
+	if radiusMeters > 0 {
+		query.Set("radius", strconv.FormatFloat(radiusMeters, 'f', -1, 64))
+	}
+	var notes []Note
+	_, err := c.do(ctx, http.MethodGet, "/v1/notes/nearby", query, nil, &notes)
+	return notes, err
+}
+
+func (c *Client) SetNoteDue(ctx context.Context, id string, dueAt time.Time) (Note, error) {
+	var note Note
+	body := map[string]time.Time{"due_at": dueAt}
+	_, err := c.do(ctx, http.MethodPut, "/v1/notes/"+url.PathEscape(id)+"/due", nil, body, &note)
+	return note, err
+}
+
+func (c *Client) ClearNoteDue(ctx context.Context, id string) (Note, error) {
+	var note Note
+	_, err := c.do(ctx, http.MethodDelete, "/v1/notes/"+url.PathEscape(id)+"/due", nil, nil, &note)
+	return note, err
+}
+
+// DeleteNotesBefore deletes the user's notes created before t and returns
+// how many were deleted.
+func (c *Client) DeleteNotesBefore(ctx context.Context, t time.Time) (int64, error) {
+	var resp struct {
+		Deleted int64 `json:"deleted"`
+	}
+	query := url.Values{"confirm": {"true"}, "created_before": {t.UTC().Format(time.RFC3339)}}
+	_, err := c.do(ctx, http.MethodDelete, "/v1/notes", query, nil, &resp)
+	return resp.Deleted, err
+}
+
+// do sends a request, retrying as described in the package comment, and
+// decodes a successful response into out, if given.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		header, retryAfter, err := c.attempt(ctx, method, u, payload, out)
+		if err == nil || attempt >= c.MaxRetries || !retryable(method, err) {
+			return header, err
+		}
+
+		wait := c.RetryBackoff << attempt
+		wait += time.Duration(rand.Int63n(int64(wait)/2 + 1))
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, u string, payload []byte, out any) (http.Header, time.Duration, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, 0, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		var errResp struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(b, &errResp) != nil || errResp.Error == "" {
+			errResp.Error = strings.TrimSpace(string(b))
+		}
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return resp.Header, time.Duration(retryAfter) * time.Second, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error, Code: errResp.Code}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, 0, err
+		}
+	}
+	return resp.Header, 0, nil
+}
+
+func retryable(method string, err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// A network error may have hit after the server acted on a POST.
+		return method != http.MethodPost && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for handler that retries without waiting
+// long.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := New(srv.URL+"/", "key")
+	c.RetryBackoff = time.Millisecond
+	return c
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "ApiKey other" {
+			t.Errorf("Authorization = %q, want %q", got, "ApiKey other")
+		}
+		json.NewEncoder(w).Encode(User{ID: "u1"})
+	})
+
+	user, err := c.WithAPIKey("other").GetUser(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "u1" {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte(`{"error":"Note was changed by someone else","code":"note_version_conflict"}`))
+	})
+
+	_, err := c.UpdateNote(context.Background(), "n1", 3, "text")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusPreconditionFailed || apiErr.Code != "note_version_conflict" || apiErr.Message != "Note was changed by someone else" {
+		t.Errorf("err = %+v", apiErr)
+	}
+	if !IsStatus(err, http.StatusPreconditionFailed) {
+		t.Error("IsStatus didn't match")
+	}
+}
+
+func TestAPIErrorWithoutJSONBody(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream exploded", http.StatusInternalServerError)
+	})
+
+	_, err := c.GetUser(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "upstream exploded" {
+		t.Errorf("err = %v, want the plain-text body as the message", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		status    int
+		wantCalls int32
+	}{
+		{name: "GET on 503", method: http.MethodGet, status: http.StatusServiceUnavailable, wantCalls: 4},
+		{name: "GET on 502", method: http.MethodGet, status: http.StatusBadGateway, wantCalls: 4},
+		{name: "GET on 429", method: http.MethodGet, status: http.StatusTooManyRequests, wantCalls: 4},
+		{name: "GET on 500", method: http.MethodGet, status: http.StatusInternalServerError, wantCalls: 1},
+		{name: "GET on 404", method: http.MethodGet, status: http.StatusNotFound, wantCalls: 1},
+		{name: "POST on 503", method: http.MethodPost, status: http.StatusServiceUnavailable, wantCalls: 4},
+		{name: "POST on 429", method: http.MethodPost, status: http.StatusTooManyRequests, wantCalls: 4},
+		{name: "POST on 502", method: http.MethodPost, status: http.StatusBadGateway, wantCalls: 1},
+		{name: "POST on 504", method: http.MethodPost, status: http.StatusGatewayTimeout, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if r.Method != tt.method {
+					t.Errorf("method = %s, want %s", r.Method, tt.method)
+				}
+				w.WriteHeader(tt.status)
+			})
+
+			var err error
+			if tt.method == http.MethodPost {
+				_, err = c.CreateNote(context.Background(), "text")
+			} else {
+				_, err = c.GetUser(context.Background())
+			}
+			if !IsStatus(err, tt.status) {
+				t.Errorf("err = %v, want status %d", err, tt.status)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("%d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Note{ID: "n1", Note: "text"})
+	})
+
+	note, err := c.CreateNote(context.Background(), "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.ID != "n1" || calls.Load() != 3 {
+		t.Errorf("note = %+v after %d calls", note, calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if waited := time.Since(first); waited < time.Second {
+			t.Errorf("retried after %v, want Retry-After's 1s", waited)
+		}
+		json.NewEncoder(w).Encode(User{ID: "u1"})
+	})
+
+	if _, err := c.GetUser(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryStopsWhenContextEnds(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetUser(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestNoRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c.MaxRetries = 0
+
+	c.GetUser(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Errorf("%d calls, want 1", got)
+	}
+}
+
+func TestListNotesPaging(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/notes" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			if got := r.URL.Query().Get("limit"); got != "2" {
+				t.Errorf("limit = %q, want 2", got)
+			}
+			w.Header().Set("X-Next-Cursor", "page2")
+			json.NewEncoder(w).Encode([]Note{{ID: "n1"}, {ID: "n2"}})
+		case "page2":
+			json.NewEncoder(w).Encode([]Note{{ID: "n3"}})
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+	})
+
+	var ids []string
+	opts := ListNotesOptions{Limit: 2}
+	for {
+		notes, next, err := c.ListNotes(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, note := range notes {
+			ids = append(ids, note.ID)
+		}
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if len(ids) != 3 || ids[0] != "n1" || ids[2] != "n3" {
+		t.Errorf("ids = %v", ids)
+	}
+}
+
+func TestGetNotesMissing(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ids"); got != "n1,n2,n3" {
+			t.Errorf("ids = %q", got)
+		}
+		w.Header().Set("X-Missing-Note-IDs", "n2,n3")
+		json.NewEncoder(w).Encode([]Note{{ID: "n1"}})
+	})
+
+	notes, missing, err := c.GetNotes(context.Background(), []string{"n1", "n2", "n3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || len(missing) != 2 || missing[1] != "n3" {
+		t.Errorf("notes = %+v, missing = %v", notes, missing)
+	}
+}
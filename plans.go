@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+const (
+	planFree = "free"
+	planPro  = "pro"
+
+	// planCacheTTL bounds how long a plan change takes to reach the rate
+	// limiter on other instances.
+	planCacheTTL = time.Minute
+)
+
+type cachedPlan struct {
+	plan    database.Plan
+	expires time.Time
+}
+
+// planCache keeps users' plans in memory so the rate limiter doesn't cost a
+// query per request.
+type planCache struct {
+	mu    sync.Mutex
+	plans map[string]cachedPlan
+}
+
+func (c *planCache) get(userID string, now time.Time) (database.Plan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.plans[userID]
+	if !ok || now.After(cached.expires) {
+		return database.Plan{}, false
+	}
+	return cached.plan, true
+}
+
+func (c *planCache) put(userID string, plan database.Plan, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.plans == nil {
+		c.plans = map[string]cachedPlan{}
+	}
+	// Entries are only replaced, never swept, so drop everything now and
+	// then rather than grow without bound.
+	if len(c.plans) > 100_000 {
+		c.plans = map[string]cachedPlan{}
+	}
+	c.plans[userID] = cachedPlan{plan: plan, expires: now.Add(planCacheTTL)}
+}
+
+func (c *planCache) forget(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.plans, userID)
+}
+
+// planFor returns userID's plan, from the cache if it's fresh.
+func (cfg *apiConfig) planFor(ctx context.Context, userID string) (database.Plan, error) {
+	now := time.Now()
+	if plan, ok := cfg.plans.get(userID, now); ok {
+		return plan, nil
+	}
+	plan, err := cfg.DB.GetPlanForUser(ctx, userID)
+	if err != nil {
+		return database.Plan{}, err
+	}
+	cfg.plans.put(userID, plan, now)
+	return plan, nil
+}
+
+// rateLimitFor returns how many requests per minute userID may make, or 0
+// for no limit. With ENFORCE_PLANS the user's plan decides; otherwise, or
+// for plans without a limit of their own, RATE_LIMIT_PER_MINUTE applies.
+func (cfg *apiConfig) rateLimitFor(ctx context.Context, userID string) int {
+	defaultLimit := cfg.runtime.Load().RateLimitPerMinute
+	if !cfg.enforcePlans {
+		return defaultLimit
+	}
+	plan, err := cfg.planFor(ctx, userID)
+	if err != nil {
+		log.Printf("Couldn't get plan for rate limiting, using the default: %v", err)
+		return defaultLimit
+	}
+	if plan.RequestsPerMinute > 0 {
+		return int(plan.RequestsPerMinute)
+	}
+	return defaultLimit
+}
+
+type planLimitError struct {
+	plan  string
+	limit int64
+}
+
+func (e *planLimitError) Error() string {
+	code, detail := e.errorCode()
+	return i18n.Message(code) + detail
+}
+
+func (e *planLimitError) errorCode() (code, detail string) {
+	return "plan_note_limit", strconv.FormatInt(e.limit, 10)
+}
+
+// checkPlanQuota returns a *planLimitError when plans are enforced and
+// userID already has as many notes as their plan, plus any bonus notes from
+// promo codes, allows.
+func (cfg *apiConfig) checkPlanQuota(ctx context.Context, userID string) error {
+	if !cfg.enforcePlans {
+		return nil
+	}
+	plan, err := cfg.DB.GetPlanForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if plan.NotesLimit <= 0 {
+		return nil
+	}
+	bonus, err := cfg.DB.GetBonusNotesForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	storage, err := cfg.DB.GetNotesStorageForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if limit := plan.NotesLimit + bonus; storage.NoteCount >= limit {
+		return &planLimitError{plan: plan.Name, limit: limit}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerPlanGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithPlan(w, r, user.ID)
+}
+
+// respondWithPlan writes userID's plan and limits. notes_limit includes
+// bonus notes, which are also reported on their own.
+func (cfg *apiConfig) respondWithPlan(w http.ResponseWriter, r *http.Request, userID string) {
+	plan, err := cfg.DB.GetPlanForUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_plan_failed", err)
+		return
+	}
+	bonus, err := cfg.DB.GetBonusNotesForUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_plan_failed", err)
+		return
+	}
+	notesLimit := plan.NotesLimit
+	if notesLimit > 0 {
+		notesLimit += bonus
+	}
+
+	type response struct {
+		Plan              string `json:"plan"`
+		NotesLimit        int64  `json:"notes_limit"`
+		BonusNotes        int64  `json:"bonus_notes"`
+		RequestsPerMinute int64  `json:"requests_per_minute"`
+		Enforced          bool   `json:"enforced"`
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		Plan:              plan.Name,
+		NotesLimit:        notesLimit,
+		BonusNotes:        bonus,
+		RequestsPerMinute: plan.RequestsPerMinute,
+		Enforced:          cfg.enforcePlans,
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/i18n"
+)
+
+// newAccountPolicy holds the stricter limits applied to accounts younger than
+// Age. A zero NotesPerHour disables the policy.
+type newAccountPolicy struct {
+	Age          time.Duration
+	NotesPerHour int
+}
+
+type noteThrottledError struct {
+	limit int
+}
+
+func (e *noteThrottledError) Error() string {
+	code, detail := e.errorCode()
+	return i18n.Message(code) + detail
+}
+
+func (e *noteThrottledError) errorCode() (code, detail string) {
+	return "new_account_note_limit", strconv.Itoa(e.limit)
+}
+
+// checkNewAccountPolicy returns a *noteThrottledError when userID is a new,
+// non-exempt account that has used up its hourly note allowance.
+func (cfg *apiConfig) checkNewAccountPolicy(ctx context.Context, userID string) error {
+	policy := cfg.runtime.Load().NewAccountPolicy
+	if policy.NotesPerHour <= 0 {
+		return nil
+	}
+
+	user, err := cfg.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	createdAt, err := parseDBTime(user.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if time.Since(createdAt) >= policy.Age {
+		return nil
+	}
+
+	exempt, err := cfg.DB.HasThrottleExemption(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if exempt != 0 {
+		return nil
+	}
+
+	count, err := cfg.DB.CountNotesForUserSince(ctx, database.CountNotesForUserSinceParams{
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	if count >= int64(policy.NotesPerHour) {
+		return &noteThrottledError{limit: policy.NotesPerHour}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerThrottleExemptionCreate(w http.ResponseWriter, r *http.Request) {
+	user, err := cfg.DB.GetUserByID(r.Context(), chi.URLParam(r, "userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_user_failed", err)
+		return
+	}
+
+	err = cfg.DB.CreateThrottleExemption(r.Context(), database.CreateThrottleExemptionParams{
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_throttle_exemption_failed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerThrottleExemptionDelete(w http.ResponseWriter, r *http.Request) {
+	n, err := cfg.DB.DeleteThrottleExemption(r.Context(), chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_throttle_exemption_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "throttle_exemption_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
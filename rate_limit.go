@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// userRateLimiter counts each user's requests per fixed window against a
+// limit given per request, so it can differ by plan. Windows are kept in
+// memory, so with several instances the limit is per instance.
+type userRateLimiter struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newUserRateLimiter(window time.Duration) *userRateLimiter {
+	return &userRateLimiter{
+		window:  window,
+		windows: map[string]*rateWindow{},
+	}
+}
+
+// take counts a request from userID against limit. It returns how many
+// requests are left in the current window, when the window resets, and
+// whether the request is allowed.
+func (l *userRateLimiter) take(userID string, limit int, now time.Time) (remaining int, reset time.Time, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget users whose windows have ended, at most once per window.
+	if now.Sub(l.lastSweep) >= l.window {
+		for id, win := range l.windows {
+			if now.Sub(win.start) >= l.window {
+				delete(l.windows, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	win := l.windows[userID]
+	if win == nil || now.Sub(win.start) >= l.window {
+		win = &rateWindow{start: now}
+		l.windows[userID] = win
+	}
+	reset = win.start.Add(l.window)
+	if win.count >= limit {
+		return 0, reset, false
+	}
+	win.count++
+	return limit - win.count, reset, true
+}
+
+// allow takes a request for userID and reports the limiter state in
+// X-RateLimit-* headers, so clients can slow down before they hit a 429. It
+// responds with the 429 itself when the user is over the limit.
+func (l *userRateLimiter) allow(w http.ResponseWriter, userID string, limit int) bool {
+	now := time.Now()
+	remaining, reset, ok := l.take(userID, limit, now)
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+		respondWithError(w, http.StatusTooManyRequests, "rate_limit_exceeded", nil)
+		return false
+	}
+	return true
+}
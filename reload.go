@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// runtimeConfig is the part of the configuration that can be reloaded without
+// a restart. Snapshots are never modified once stored; a reload swaps in a
+// whole new one, so a request never sees a mix of old and new settings.
+type runtimeConfig struct {
+	SanitizeNotes      bool
+	NewAccountPolicy   newAccountPolicy
+	ShedP99            time.Duration
+	ShedDBErrorRate    float64
+	CORSOrigins        []string
+	ShadowPercent      int
+	RateLimitPerMinute int
+}
+
+// loadRuntimeConfig reads the reloadable settings from the environment,
+// returning an error instead of exiting if any of them is invalid.
+func loadRuntimeConfig() (*runtimeConfig, error) {
+	var errs []error
+	intSetting := func(name string, fallback int) int {
+		n, err := parseEnvInt(name, fallback)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("%s can't be negative", name)
+		}
+		errs = append(errs, err)
+		return n
+	}
+
+	sanitizeNotes, err := parseEnvBool("SANITIZE_NOTES", false)
+	errs = append(errs, err)
+
+	rc := &runtimeConfig{
+		SanitizeNotes: sanitizeNotes,
+		NewAccountPolicy: newAccountPolicy{
+			Age:          time.Duration(intSetting("NEW_ACCOUNT_DAYS", 7)) * 24 * time.Hour,
+			NotesPerHour: intSetting("NEW_ACCOUNT_NOTES_PER_HOUR", 30),
+		},
+		ShedP99:            time.Duration(intSetting("SHED_P99_MS", 2000)) * time.Millisecond,
+		ShedDBErrorRate:    float64(intSetting("SHED_DB_ERROR_PERCENT", 20)) / 100,
+		CORSOrigins:        []string{"https://*", "http://*"},
+		ShadowPercent:      intSetting("SHADOW_PERCENT", 0),
+		RateLimitPerMinute: intSetting("RATE_LIMIT_PER_MINUTE", 0),
+	}
+	if rc.ShadowPercent > 100 {
+		errs = append(errs, errors.New("SHADOW_PERCENT can't be more than 100"))
+	}
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		rc.CORSOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			origin = strings.ToLower(strings.TrimSpace(origin))
+			if strings.Count(origin, "*") > 1 {
+				errs = append(errs, fmt.Errorf("CORS origin %q can contain at most one *", origin))
+			}
+			if origin != "" {
+				rc.CORSOrigins = append(rc.CORSOrigins, origin)
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// processEnv holds the names of the variables set before main loaded .env.
+// Package variables are initialized before main runs, so this is the
+// environment the process was started with.
+var processEnv = envNames()
+
+func envNames() map[string]bool {
+	names := map[string]bool{}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		names[name] = true
+	}
+	return names
+}
+
+// reloadDotenv applies the current .env with the same precedence as
+// godotenv.Load at startup: variables from the process environment win.
+// Variables that came from .env and have since been removed from it are
+// unset, so they go back to their defaults.
+func reloadDotenv() error {
+	env, err := godotenv.Read(".env")
+	if err != nil {
+		return err
+	}
+	for name := range envNames() {
+		if _, ok := env[name]; !ok && !processEnv[name] {
+			os.Unsetenv(name)
+		}
+	}
+	for name, value := range env {
+		if !processEnv[name] {
+			os.Setenv(name, value)
+		}
+	}
+	return nil
+}
+
+// reloadConfig re-reads .env and the environment and swaps in the new
+// settings. The running config is left alone if the new one is invalid.
+func (cfg *apiConfig) reloadConfig() (*runtimeConfig, error) {
+	if err := reloadDotenv(); err != nil {
+		log.Printf("warning: reloading without .env: %v", err)
+	}
+
+	rc, err := loadRuntimeConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.runtime.Store(rc)
+	cfg.shedder.setLimits(rc.ShedP99, rc.ShedDBErrorRate)
+	return rc, nil
+}
+
+// reloadOnSIGHUP reloads the config every time the process receives SIGHUP.
+func (cfg *apiConfig) reloadOnSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if _, err := cfg.reloadConfig(); err != nil {
+			log.Printf("Config reload failed, keeping current config: %v", err)
+			continue
+		}
+		log.Println("Config reloaded")
+	}
+}
+
+// allowOrigin checks a CORS origin against the current snapshot. Patterns
+// may contain one * wildcard, as with cors.Options.AllowedOrigins.
+func (cfg *apiConfig) allowOrigin(r *http.Request, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range cfg.runtime.Load().CORSOrigins {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if !wildcard {
+			if origin == pattern {
+				return true
+			}
+			continue
+		}
+		if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+type runtimeConfigResponse struct {
+	SanitizeNotes          bool     `json:"sanitize_notes"`
+	NewAccountDays         int      `json:"new_account_days"`
+	NewAccountNotesPerHour int      `json:"new_account_notes_per_hour"`
+	ShedP99Ms              int64    `json:"shed_p99_ms"`
+	ShedDBErrorPercent     int      `json:"shed_db_error_percent"`
+	CORSAllowedOrigins     []string `json:"cors_allowed_origins"`
+	ShadowPercent          int      `json:"shadow_percent"`
+	RateLimitPerMinute     int      `json:"rate_limit_per_minute"`
+}
+
+func runtimeConfigToResponse(rc *runtimeConfig) runtimeConfigResponse {
+	return runtimeConfigResponse{
+		SanitizeNotes:          rc.SanitizeNotes,
+		NewAccountDays:         int(rc.NewAccountPolicy.Age / (24 * time.Hour)),
+		NewAccountNotesPerHour: rc.NewAccountPolicy.NotesPerHour,
+		ShedP99Ms:              rc.ShedP99.Milliseconds(),
+		ShedDBErrorPercent:     int(rc.ShedDBErrorRate*100 + 0.5),
+		CORSAllowedOrigins:     rc.CORSOrigins,
+		ShadowPercent:          rc.ShadowPercent,
+		RateLimitPerMinute:     rc.RateLimitPerMinute,
+	}
+}
+
+func (cfg *apiConfig) handlerConfigGet(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, runtimeConfigToResponse(cfg.runtime.Load()))
+}
+
+func (cfg *apiConfig) handlerConfigReload(w http.ResponseWriter, r *http.Request) {
+	rc, err := cfg.reloadConfig()
+	if err != nil {
+		respondWithErrorDetail(w, http.StatusBadRequest, "invalid_config", err.Error(), nil)
+		return
+	}
+	log.Println("Config reloaded")
+	respondWithJSON(w, http.StatusOK, runtimeConfigToResponse(rc))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestReloadDotenvKeepsProcessEnvironment(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("RELOAD_TEST_FROM_PROCESS", "process")
+	processEnv["RELOAD_TEST_FROM_PROCESS"] = true
+	t.Cleanup(func() {
+		delete(processEnv, "RELOAD_TEST_FROM_PROCESS")
+		os.Unsetenv("RELOAD_TEST_FROM_DOTENV")
+		os.Unsetenv("RELOAD_TEST_REMOVED")
+	})
+
+	writeDotenv := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(".env", []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := reloadDotenv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeDotenv("RELOAD_TEST_FROM_PROCESS=dotenv\nRELOAD_TEST_FROM_DOTENV=first\nRELOAD_TEST_REMOVED=yes\n")
+	if got := os.Getenv("RELOAD_TEST_FROM_PROCESS"); got != "process" {
+		t.Errorf("process variable = %q after reload, want %q", got, "process")
+	}
+	if got := os.Getenv("RELOAD_TEST_FROM_DOTENV"); got != "first" {
+		t.Errorf(".env variable = %q, want %q", got, "first")
+	}
+
+	writeDotenv("RELOAD_TEST_FROM_DOTENV=second\n")
+	if got := os.Getenv("RELOAD_TEST_FROM_DOTENV"); got != "second" {
+		t.Errorf(".env variable = %q after editing .env, want %q", got, "second")
+	}
+	if _, ok := os.LookupEnv("RELOAD_TEST_REMOVED"); ok {
+		t.Error("variable removed from .env is still set")
+	}
+	if got := os.Getenv("RELOAD_TEST_FROM_PROCESS"); got != "process" {
+		t.Errorf("process variable = %q after removing it from .env, want %q", got, "process")
+	}
+}
+
+func TestRateLimitFollowsReloads(t *testing.T) {
+	cfg := &apiConfig{}
+	t.Setenv("RATE_LIMIT_PER_MINUTE", "60")
+	rc, err := loadRuntimeConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.runtime.Store(rc)
+	if got := cfg.rateLimitFor(context.Background(), testUser.ID); got != 60 {
+		t.Errorf("rate limit = %d, want 60", got)
+	}
+
+	t.Setenv("RATE_LIMIT_PER_MINUTE", "0")
+	if rc, err = loadRuntimeConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.runtime.Store(rc)
+	if got := cfg.rateLimitFor(context.Background(), testUser.ID); got != 0 {
+		t.Errorf("rate limit after reload = %d, want 0", got)
+	}
+}
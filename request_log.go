@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// requestRecord is what the request log keeps about each request. Query
+// strings and bodies are left out, since they can hold tokens and note text.
+type requestRecord struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	LatencyMS float64   `json:"latency_ms"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// requestLog keeps the most recent requests in a fixed-size ring buffer, for
+// triaging reports like "it failed five minutes ago".
+type requestLog struct {
+	mu      sync.Mutex
+	records []requestRecord
+	next    int
+	full    bool
+}
+
+func newRequestLog(size int) *requestLog {
+	return &requestLog{records: make([]requestRecord, size)}
+}
+
+func (l *requestLog) add(rec requestRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[l.next] = rec
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// recent returns up to limit records matching keep, newest first.
+func (l *requestLog) recent(limit int, keep func(requestRecord) bool) []requestRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.records)
+	}
+	result := []requestRecord{}
+	for i := 1; i <= n && len(result) < limit; i++ {
+		rec := l.records[(l.next-i+len(l.records))%len(l.records)]
+		if keep(rec) {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+type requestUserKey struct{}
+
+// setRequestUser records who made the request for the request log. The auth
+// middlewares call it once they know the user.
+func setRequestUser(r *http.Request, userID string) {
+	if user, ok := r.Context().Value(requestUserKey{}).(*string); ok {
+		*user = userID
+	}
+}
+
+func (l *requestLog) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		user := new(string)
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user)))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		var route string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+		l.add(requestRecord{
+			Time:      start.UTC(),
+			Method:    r.Method,
+			Route:     route,
+			Path:      r.URL.Path,
+			Status:    rec.status,
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			UserID:    *user,
+		})
+	})
+}
+
+// handlerRequestsGet lists recent requests, newest first. ?limit= caps the
+// count (default 100), ?user_id= filters to one user and ?min_status=500
+// shows only failures.
+func (cfg *apiConfig) handlerRequestsGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			respondWithError(w, http.StatusBadRequest, "invalid_limit", err)
+			return
+		}
+		limit = n
+	}
+	var minStatus int
+	if s := query.Get("min_status"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid_min_status", err)
+			return
+		}
+		minStatus = n
+	}
+	userID := query.Get("user_id")
+
+	respondWithJSON(w, http.StatusOK, cfg.requestLog.recent(limit, func(rec requestRecord) bool {
+		return rec.Status >= minStatus && (userID == "" || rec.UserID == userID)
+	}))
+}
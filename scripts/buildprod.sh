@@ -1,3 +1,7 @@
 #!/bin/bash
 
-CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o notely
+LDFLAGS="-X main.version=${VERSION:-dev}"
+LDFLAGS="$LDFLAGS -X main.gitSHA=$(git rev-parse HEAD 2>/dev/null || echo unknown)"
+LDFLAGS="$LDFLAGS -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+
+CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "$LDFLAGS" -o notely
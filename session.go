@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	sessionCookie   = "notely_session"
+	sessionLifetime = 30 * 24 * time.Hour
+)
+
+// Only a hash of the session token is stored, so a leaked database can't be
+// used to log in.
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession creates a session for user and sets its cookie.
+func (cfg *apiConfig) startSession(w http.ResponseWriter, r *http.Request, user database.User) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b)
+
+	now := time.Now().UTC()
+	err := cfg.DB.CreateSession(r.Context(), database.CreateSessionParams{
+		TokenHash: hashSessionToken(token),
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(sessionLifetime).Format(time.RFC3339),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/app",
+		MaxAge:   int(sessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// endSession deletes the session in the request, if any, and clears its
+// cookie.
+func (cfg *apiConfig) endSession(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/app", MaxAge: -1})
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	return cfg.DB.DeleteSession(r.Context(), hashSessionToken(cookie.Value))
+}
+
+// sameOrigin reports whether a form post came from this site. Browsers send
+// Origin on cross-site posts, so a missing header is allowed; together with
+// the SameSite cookie this guards against CSRF.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// middlewareSession is the cookie-based counterpart of middlewareAuth for the
+// HTML UI. Requests without a valid session are sent to the login page.
+func (cfg *apiConfig) middlewareSession(handler authedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && !sameOrigin(r) {
+			renderAppError(w, http.StatusForbidden, "Cross-site request refused")
+			return
+		}
+
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			http.Redirect(w, r, "/app/login", http.StatusSeeOther)
+			return
+		}
+		user, err := cfg.DB.GetUserBySession(r.Context(), database.GetUserBySessionParams{
+			TokenHash: hashSessionToken(cookie.Value),
+			ExpiresAt: time.Now().UTC().Format(time.RFC3339),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Redirect(w, r, "/app/login", http.StatusSeeOther)
+			return
+		}
+		if err != nil {
+			log.Printf("Couldn't get session: %s", err)
+			renderAppError(w, http.StatusInternalServerError, "Couldn't get session")
+			return
+		}
+
+		setRequestUser(r, user.ID)
+		suspension, suspended, err := cfg.suspensionFor(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("Couldn't check account status: %s", err)
+			renderAppError(w, http.StatusInternalServerError, "Couldn't check account status")
+			return
+		}
+		if suspended {
+			renderAppError(w, http.StatusForbidden, "Account suspended: "+suspension.Reason)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// deleteExpiredSessions also clears out expired impersonation tokens, which
+// are sessions of a sort.
+func (cfg *apiConfig) deleteExpiredSessions(ctx context.Context) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := cfg.DB.DeleteExpiredSessions(ctx, now); err != nil {
+		return err
+	}
+	_, err := cfg.DB.DeleteExpiredImpersonationTokens(ctx, now)
+	return err
+}
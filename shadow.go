@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	shadowTimeout = 10 * time.Second
+	// Shadow reads that would exceed this many in flight are skipped rather
+	// than queued, so a slow candidate can't pile up work.
+	shadowMaxInFlight = 4
+)
+
+var shadowSlots = make(chan struct{}, shadowMaxInFlight)
+
+// shadowRead runs candidate in the background for SHADOW_PERCENT of calls,
+// after the real response has been served, and passes its result to compare.
+// Nothing it does can affect the user's response.
+func (cfg *apiConfig) shadowRead(name string, candidate func(context.Context) error) {
+	percent := cfg.runtime.Load().ShadowPercent
+	if percent <= 0 || rand.Intn(100) >= percent {
+		return
+	}
+	select {
+	case shadowSlots <- struct{}{}:
+	default:
+		cfg.metrics.Count("shadow.skipped", 1, "read:"+name)
+		return
+	}
+
+	go func() {
+		defer func() { <-shadowSlots }()
+		ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+		defer cancel()
+		if err := candidate(ctx); err != nil {
+			log.Printf("Shadow read %s failed: %v", name, err)
+			cfg.metrics.Count("shadow.errors", 1, "read:"+name)
+		}
+	}()
+}
+
+// shadowNotesList checks that paging through a user's notes with keyset
+// pagination returns exactly the notes served by the unpaginated list.
+func (cfg *apiConfig) shadowNotesList(userID string, served []database.Note) {
+	cfg.shadowRead("notes_list", func(ctx context.Context) error {
+		want := make(map[string]database.Note, len(served))
+		for _, note := range served {
+			want[note.ID] = note
+		}
+
+		cursor := noteCursor{}
+		var got, differing int
+		for {
+			page, err := cfg.DB.GetNotesForUserAfter(ctx, database.GetNotesForUserAfterParams{
+				UserID:      userID,
+				CreatedAt:   cursor.CreatedAt,
+				CreatedAt_2: cursor.CreatedAt,
+				ID:          cursor.ID,
+				Limit:       maxPageSize,
+			})
+			if err != nil {
+				return err
+			}
+			for _, note := range page {
+				got++
+				if want[note.ID] != note {
+					differing++
+				}
+			}
+			if len(page) < maxPageSize {
+				break
+			}
+			last := page[len(page)-1]
+			cursor = noteCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+
+		// Notes written between the two reads also show up here; they are
+		// rare enough that the log is still useful.
+		if got != len(served) || differing > 0 {
+			log.Printf("Shadow read notes_list diverged for user %s: served %d notes, pagination returned %d, %d differing", userID, len(served), got, differing)
+			cfg.metrics.Count("shadow.diverged", 1, "read:notes_list")
+			return nil
+		}
+		cfg.metrics.Count("shadow.matched", 1, "read:notes_list")
+		return nil
+	})
+}
@@ -0,0 +1,19 @@
+-- name: AddAPIUsage :exec
+INSERT INTO api_usage (user_id, day, requests, bytes)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, day) DO UPDATE
+SET requests = api_usage.requests + excluded.requests, bytes = api_usage.bytes + excluded.bytes;
+--
+
+-- name: GetAPIUsageForUser :many
+SELECT * FROM api_usage WHERE user_id = ? AND day >= ? ORDER BY day;
+--
+
+-- name: GetAPIUsageByUser :many
+SELECT user_id, CAST(SUM(requests) AS INTEGER) AS requests, CAST(SUM(bytes) AS INTEGER) AS bytes
+FROM api_usage
+WHERE day >= ? AND day <= ?
+GROUP BY user_id
+ORDER BY requests DESC
+LIMIT ?;
+--
@@ -0,0 +1,4 @@
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (created_at, actor, action, subject_type, subject_id, details)
+VALUES (?, ?, ?, ?, ?, ?);
+--
@@ -0,0 +1,24 @@
+-- name: CreateComment :exec
+INSERT INTO comments (id, created_at, updated_at, body, note_id, user_id)
+VALUES (?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetComment :one
+SELECT * FROM comments WHERE id = ?;
+--
+
+-- name: GetCommentsForNote :many
+SELECT * FROM comments WHERE note_id = ? ORDER BY created_at, id;
+--
+
+-- name: UpdateComment :exec
+UPDATE comments SET body = ?, updated_at = ? WHERE id = ?;
+--
+
+-- name: DeleteComment :exec
+DELETE FROM comments WHERE id = ?;
+--
+
+-- name: GetCommentsByUser :many
+SELECT * FROM comments WHERE user_id = ? ORDER BY created_at;
+--
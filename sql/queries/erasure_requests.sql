@@ -0,0 +1,34 @@
+-- name: UpsertErasureRequest :exec
+INSERT INTO erasure_requests (user_id, created_at, confirmation_token)
+VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+    created_at = excluded.created_at,
+    confirmation_token = excluded.confirmation_token,
+    confirmed_at = NULL,
+    erase_after = NULL;
+--
+
+-- name: GetErasureRequest :one
+SELECT * FROM erasure_requests WHERE user_id = ?;
+--
+
+-- name: ConfirmErasureRequest :execrows
+UPDATE erasure_requests SET confirmed_at = ?, erase_after = ?
+WHERE user_id = ? AND confirmation_token = ? AND confirmed_at IS NULL AND created_at > ?;
+--
+
+-- name: DeleteErasureRequest :execrows
+DELETE FROM erasure_requests WHERE user_id = ?;
+--
+
+-- name: GetDueErasures :many
+SELECT user_id FROM erasure_requests
+WHERE erase_after IS NOT NULL AND erase_after <= ?
+AND NOT EXISTS (SELECT 1 FROM legal_holds WHERE subject_type = 'user' AND subject_id = erasure_requests.user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM legal_holds JOIN notes ON notes.id = legal_holds.subject_id
+    WHERE legal_holds.subject_type = 'note' AND notes.user_id = erasure_requests.user_id
+)
+ORDER BY erase_after
+LIMIT ?;
+--
@@ -0,0 +1,26 @@
+-- name: CreateExport :exec
+INSERT INTO exports (id, created_at, updated_at, status, kind, user_id)
+VALUES (?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetExport :one
+SELECT id, created_at, updated_at, status, error, kind, user_id FROM exports WHERE id = ?;
+--
+
+-- name: GetExportContent :one
+SELECT content FROM exports WHERE id = ?;
+--
+
+-- name: ClaimPendingExport :one
+UPDATE exports SET status = 'running', updated_at = ?
+WHERE id = (SELECT id FROM exports WHERE status = 'pending' ORDER BY created_at LIMIT 1)
+RETURNING id, user_id, kind;
+--
+
+-- name: FinishExport :exec
+UPDATE exports SET status = ?, error = ?, content = ?, updated_at = ? WHERE id = ?;
+--
+
+-- name: ResetRunningExports :exec
+UPDATE exports SET status = 'pending', updated_at = ? WHERE status = 'running';
+--
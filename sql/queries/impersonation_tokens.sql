@@ -0,0 +1,12 @@
+-- name: CreateImpersonationToken :exec
+INSERT INTO impersonation_tokens (token_hash, created_at, expires_at, user_id, reason, allow_writes)
+VALUES (?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetImpersonationToken :one
+SELECT * FROM impersonation_tokens WHERE token_hash = ? AND expires_at > ?;
+--
+
+-- name: DeleteExpiredImpersonationTokens :execrows
+DELETE FROM impersonation_tokens WHERE expires_at <= ?;
+--
@@ -0,0 +1,27 @@
+-- name: CreateLegalHold :execrows
+INSERT INTO legal_holds (subject_type, subject_id, created_at, reason)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (subject_type, subject_id) DO NOTHING;
+--
+
+-- name: DeleteLegalHold :execrows
+DELETE FROM legal_holds WHERE subject_type = ? AND subject_id = ?;
+--
+
+-- name: GetLegalHolds :many
+SELECT * FROM legal_holds ORDER BY created_at;
+--
+
+-- name: IsUserOnLegalHold :one
+SELECT EXISTS (
+    SELECT 1 FROM legal_holds WHERE subject_type = 'user' AND subject_id = ?1
+    UNION ALL
+    SELECT 1 FROM legal_holds
+    JOIN notes ON notes.id = legal_holds.subject_id
+    WHERE legal_holds.subject_type = 'note' AND notes.user_id = ?1
+) AS on_hold;
+--
+
+-- name: HasLegalHold :one
+SELECT EXISTS (SELECT 1 FROM legal_holds WHERE subject_type = ? AND subject_id = ?) AS on_hold;
+--
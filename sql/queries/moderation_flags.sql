@@ -0,0 +1,17 @@
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (note_id, created_at, reason)
+VALUES (?, ?, ?)
+ON CONFLICT (note_id) DO UPDATE SET created_at = excluded.created_at, reason = excluded.reason;
+--
+
+-- name: GetFlaggedNotes :many
+SELECT notes.id, notes.user_id, notes.note, moderation_flags.reason, moderation_flags.created_at AS flagged_at
+FROM moderation_flags
+JOIN notes ON notes.id = moderation_flags.note_id
+ORDER BY moderation_flags.created_at
+LIMIT ?;
+--
+
+-- name: DeleteModerationFlag :execrows
+DELETE FROM moderation_flags WHERE note_id = ?;
+--
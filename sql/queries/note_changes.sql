@@ -0,0 +1,14 @@
+-- name: GetLatestNoteChangeSeq :one
+SELECT CAST(COALESCE(MAX(seq), 0) AS INTEGER) AS seq FROM note_changes;
+--
+
+-- name: GetNoteChangesForUserSince :many
+SELECT * FROM note_changes
+WHERE user_id = ? AND seq > ?
+ORDER BY seq
+LIMIT ?;
+--
+
+-- name: DeleteNoteChangesForUser :exec
+DELETE FROM note_changes WHERE user_id = ?;
+--
@@ -0,0 +1,21 @@
+-- name: CreateNoteLink :exec
+INSERT OR IGNORE INTO note_links (source_note_id, target_note_id, created_at)
+VALUES (?, ?, ?);
+--
+
+-- name: GetBacklinksForUser :many
+SELECT notes.* FROM notes
+JOIN note_links ON notes.id = note_links.source_note_id
+WHERE note_links.target_note_id = ? AND notes.user_id = ?
+ORDER BY notes.created_at;
+--
+
+-- name: GetNoteLinksForUser :many
+SELECT note_links.source_note_id, note_links.target_note_id FROM note_links
+JOIN notes ON notes.id = note_links.source_note_id
+WHERE notes.user_id = ?;
+--
+
+-- name: DeleteNoteLinksFromSource :exec
+DELETE FROM note_links WHERE source_note_id = ?;
+--
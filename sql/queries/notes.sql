@@ -1,6 +1,6 @@
 -- name: CreateNote :exec
-INSERT INTO notes (id, created_at, updated_at, note, user_id)
-VALUES (?, ?, ?, ?, ?);
+INSERT INTO notes (id, created_at, updated_at, note, user_id, slug, language, title, title_custom)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
 --
 
 -- name: GetNote :one
@@ -10,3 +10,127 @@ SELECT * FROM notes WHERE id = ?;
 -- name: GetNotesForUser :many
 SELECT * FROM notes WHERE user_id = ?;
 --
+
+-- name: GetNotesStorageForUser :one
+SELECT COUNT(*) AS note_count, CAST(COALESCE(SUM(LENGTH(CAST(note AS BLOB))), 0) AS INTEGER) AS bytes
+FROM notes WHERE user_id = ?;
+--
+
+-- name: GetLargestNotesForUser :many
+SELECT id, created_at, CAST(LENGTH(CAST(note AS BLOB)) AS INTEGER) AS bytes
+FROM notes WHERE user_id = ?
+ORDER BY bytes DESC
+LIMIT ?;
+--
+
+-- name: GetNotesForUserAfter :many
+SELECT * FROM notes
+WHERE user_id = ? AND (created_at > ? OR (created_at = ? AND id > ?))
+ORDER BY created_at, id
+LIMIT ?;
+--
+
+-- name: DeleteNotesForUserBefore :execrows
+DELETE FROM notes WHERE id IN (
+    SELECT id FROM notes WHERE user_id = ? AND created_at < ?
+    AND id NOT IN (SELECT subject_id FROM legal_holds WHERE subject_type = 'note')
+    LIMIT ?
+);
+--
+
+-- name: SetNoteDueAt :exec
+UPDATE notes SET due_at = ?, reminded_at = NULL, updated_at = ?, version = version + 1 WHERE id = ?;
+--
+
+-- name: GetDueReminders :many
+SELECT * FROM notes
+WHERE due_at IS NOT NULL AND due_at <= ? AND reminded_at IS NULL
+ORDER BY due_at
+LIMIT ?;
+--
+
+-- name: MarkNoteReminded :exec
+UPDATE notes SET reminded_at = ? WHERE id = ?;
+--
+
+-- name: GetLatestNotesForUser :many
+SELECT * FROM notes WHERE user_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+--
+
+-- name: GetNotesWithDueAtForUser :many
+SELECT * FROM notes
+WHERE user_id = ? AND due_at IS NOT NULL
+ORDER BY due_at;
+--
+
+-- name: CountNotesForUserSince :one
+SELECT COUNT(*) FROM notes WHERE user_id = ? AND created_at >= ?;
+--
+
+-- name: UpdateNote :execrows
+UPDATE notes SET note = ?, language = ?, title = ?, title_custom = ?, updated_at = ?, version = version + 1
+WHERE id = ? AND version = ?;
+--
+
+-- name: GetNoteBySlug :one
+SELECT * FROM notes WHERE slug = ?;
+--
+
+-- name: GetNotesWithoutSlug :many
+SELECT id FROM notes WHERE slug IS NULL LIMIT ?;
+--
+
+-- name: SetNoteSlug :exec
+UPDATE notes SET slug = ? WHERE id = ?;
+--
+
+-- name: GetNotesWithoutLanguage :many
+SELECT id, note FROM notes WHERE language IS NULL LIMIT ?;
+--
+
+-- name: SetNoteLanguage :exec
+UPDATE notes SET language = ? WHERE id = ?;
+--
+
+-- name: GetNotesWithoutTitle :many
+SELECT id, note FROM notes WHERE title IS NULL LIMIT ?;
+--
+
+-- name: SetNoteTitle :exec
+UPDATE notes SET title = ? WHERE id = ? AND title IS NULL;
+--
+
+-- name: SetNoteProperties :exec
+UPDATE notes SET properties = ?, updated_at = ?, version = version + 1 WHERE id = ?;
+--
+
+-- name: FilterNotesForUser :many
+SELECT * FROM notes
+WHERE user_id = sqlc.arg(user_id)
+AND (sqlc.narg(ids) IS NULL OR id IN (SELECT value FROM json_each(sqlc.narg(ids))))
+AND (sqlc.narg(language) IS NULL OR language = sqlc.narg(language))
+AND (sqlc.narg(title) IS NULL OR title LIKE sqlc.narg(title) ESCAPE '\')
+AND (sqlc.narg(due_before) IS NULL OR (due_at IS NOT NULL AND due_at < sqlc.narg(due_before)))
+AND (sqlc.narg(project) IS NULL OR id IN (
+    SELECT id FROM notes WHERE user_id = sqlc.arg(user_id) AND property_project = sqlc.narg(project)
+))
+AND NOT EXISTS (
+    SELECT 1 FROM json_each(CAST(sqlc.arg(properties) AS TEXT)) AS want
+    WHERE json_extract(notes.properties, '$."' || want.key || '"') IS NOT want.value
+)
+ORDER BY CASE WHEN sqlc.narg(due_before) IS NULL THEN NULL ELSE due_at END, created_at DESC, id DESC;
+--
+
+-- name: SetNoteLocation :exec
+UPDATE notes SET latitude = ?, longitude = ?, updated_at = ?, version = version + 1 WHERE id = ?;
+--
+
+-- name: GetNotesForUserInBox :many
+SELECT * FROM notes
+WHERE user_id = ?
+AND latitude BETWEEN CAST(sqlc.arg(min_lat) AS REAL) AND CAST(sqlc.arg(max_lat) AS REAL)
+AND (longitude BETWEEN CAST(sqlc.arg(min_lng) AS REAL) AND CAST(sqlc.arg(max_lng) AS REAL)
+    OR longitude BETWEEN CAST(sqlc.arg(wrapped_min_lng) AS REAL) AND CAST(sqlc.arg(wrapped_max_lng) AS REAL));
+--
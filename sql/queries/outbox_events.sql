@@ -0,0 +1,24 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (created_at, payload)
+VALUES (?, ?);
+--
+
+-- name: ClaimOutboxEvents :many
+UPDATE outbox_events
+SET claimed_until = CAST(sqlc.arg(claimed_until) AS TEXT), attempts = attempts + 1
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE sent_at IS NULL AND (claimed_until IS NULL OR claimed_until < CAST(sqlc.arg(now) AS TEXT))
+    ORDER BY id
+    LIMIT sqlc.arg(limit)
+)
+RETURNING *;
+--
+
+-- name: MarkOutboxEventSent :exec
+UPDATE outbox_events SET sent_at = ? WHERE id = ?;
+--
+
+-- name: DeleteSentOutboxEvents :exec
+DELETE FROM outbox_events WHERE sent_at IS NOT NULL AND sent_at < ?;
+--
@@ -0,0 +1,37 @@
+-- name: GetPlanForUser :one
+SELECT * FROM plans
+WHERE name = COALESCE((SELECT plan FROM user_plans WHERE user_id = ?), 'free');
+--
+
+-- name: GetPlan :one
+SELECT * FROM plans WHERE name = ?;
+--
+
+-- name: SetUserPlan :exec
+INSERT INTO user_plans (user_id, plan, stripe_customer_id, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET plan = excluded.plan,
+    stripe_customer_id = COALESCE(excluded.stripe_customer_id, user_plans.stripe_customer_id),
+    updated_at = excluded.updated_at;
+--
+
+-- name: GetUserIDByStripeCustomer :one
+SELECT user_id FROM user_plans WHERE stripe_customer_id = ?;
+--
+
+-- name: GetStripeCustomerForUser :one
+SELECT stripe_customer_id FROM user_plans WHERE user_id = ?;
+--
+
+-- name: GetBonusNotesForUser :one
+SELECT CAST(COALESCE((SELECT bonus_notes FROM user_plans WHERE user_id = ?), 0) AS INTEGER) AS bonus_notes;
+--
+
+-- name: AddUserBonusNotes :exec
+INSERT INTO user_plans (user_id, plan, bonus_notes, updated_at)
+VALUES (?, 'free', ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET bonus_notes = user_plans.bonus_notes + excluded.bonus_notes,
+    updated_at = excluded.updated_at;
+--
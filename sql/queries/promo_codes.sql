@@ -0,0 +1,26 @@
+-- name: CreatePromoCode :execrows
+INSERT INTO promo_codes (code, plan, bonus_notes, max_uses, expires_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (code) DO NOTHING;
+--
+
+-- name: GetPromoCode :one
+SELECT * FROM promo_codes WHERE code = ?;
+--
+
+-- name: GetPromoCodes :many
+SELECT * FROM promo_codes ORDER BY created_at DESC;
+--
+
+-- name: ClaimPromoCode :execrows
+UPDATE promo_codes SET uses = uses + 1
+WHERE code = ?
+AND (max_uses = 0 OR uses < max_uses)
+AND (expires_at IS NULL OR expires_at > ?);
+--
+
+-- name: CreatePromoRedemption :execrows
+INSERT INTO promo_redemptions (code, user_id, redeemed_at)
+VALUES (?, ?, ?)
+ON CONFLICT (code, user_id) DO NOTHING;
+--
@@ -0,0 +1,20 @@
+-- name: UpsertReaction :exec
+INSERT INTO reactions (note_id, user_id, emoji, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (note_id, user_id) DO UPDATE SET emoji = excluded.emoji, created_at = excluded.created_at;
+--
+
+-- name: DeleteReaction :execrows
+DELETE FROM reactions WHERE note_id = ? AND user_id = ?;
+--
+
+-- name: GetReactionCountsForUserNotes :many
+SELECT reactions.note_id, reactions.emoji, COUNT(*) AS count FROM reactions
+JOIN notes ON notes.id = reactions.note_id
+WHERE notes.user_id = ?
+GROUP BY reactions.note_id, reactions.emoji;
+--
+
+-- name: GetReactionsByUser :many
+SELECT * FROM reactions WHERE user_id = ? ORDER BY created_at;
+--
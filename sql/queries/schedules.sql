@@ -0,0 +1,24 @@
+-- name: CreateSchedule :exec
+INSERT INTO schedules (id, created_at, updated_at, spec, template_values, next_run_at, template_id, user_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetSchedule :one
+SELECT * FROM schedules WHERE id = ?;
+--
+
+-- name: GetSchedulesForUser :many
+SELECT * FROM schedules WHERE user_id = ? ORDER BY created_at;
+--
+
+-- name: DeleteScheduleForUser :execrows
+DELETE FROM schedules WHERE id = ? AND user_id = ?;
+--
+
+-- name: GetDueSchedules :many
+SELECT * FROM schedules WHERE next_run_at <= ? ORDER BY next_run_at LIMIT ?;
+--
+
+-- name: UpdateScheduleNextRun :exec
+UPDATE schedules SET next_run_at = ?, updated_at = ? WHERE id = ?;
+--
@@ -0,0 +1,18 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, created_at, expires_at, user_id)
+VALUES (?, ?, ?, ?);
+--
+
+-- name: GetUserBySession :one
+SELECT users.* FROM users
+JOIN sessions ON users.id = sessions.user_id
+WHERE sessions.token_hash = ? AND sessions.expires_at > ?;
+--
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = ?;
+--
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions WHERE expires_at <= ?;
+--
@@ -0,0 +1,9 @@
+-- name: GetSetting :one
+SELECT * FROM settings WHERE key = ?;
+--
+
+-- name: UpsertSetting :exec
+INSERT INTO settings (key, value, updated_at)
+VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at;
+--
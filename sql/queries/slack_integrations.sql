@@ -0,0 +1,16 @@
+-- name: CreateSlackIntegration :exec
+INSERT INTO slack_integrations (id, created_at, updated_at, webhook_url, event_types, user_id)
+VALUES (?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetSlackIntegration :one
+SELECT * FROM slack_integrations WHERE id = ?;
+--
+
+-- name: GetSlackIntegrationsForUser :many
+SELECT * FROM slack_integrations WHERE user_id = ? ORDER BY created_at;
+--
+
+-- name: DeleteSlackIntegrationForUser :execrows
+DELETE FROM slack_integrations WHERE id = ? AND user_id = ?;
+--
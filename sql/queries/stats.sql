@@ -0,0 +1,24 @@
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+--
+
+-- name: CountNotes :one
+SELECT COUNT(*) FROM notes;
+--
+
+-- name: CountActiveUsersOnDay :one
+SELECT COUNT(DISTINCT user_id) FROM api_usage WHERE day = ?;
+--
+
+-- name: GetNotesCreatedPerDay :many
+SELECT CAST(substr(created_at, 1, 10) AS TEXT) AS day, COUNT(*) AS notes
+FROM notes
+WHERE created_at >= ?
+GROUP BY day
+ORDER BY day;
+--
+
+-- name: GetDatabaseSize :one
+SELECT CAST(page_count * page_size AS INTEGER) AS bytes
+FROM pragma_page_count(), pragma_page_size();
+--
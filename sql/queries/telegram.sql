@@ -0,0 +1,27 @@
+-- name: CreateTelegramLinkCode :exec
+INSERT INTO telegram_link_codes (code, created_at, expires_at, user_id)
+VALUES (?, ?, ?, ?);
+--
+
+-- name: ClaimTelegramLinkCode :one
+DELETE FROM telegram_link_codes WHERE code = ? AND expires_at > ?
+RETURNING user_id;
+--
+
+-- name: DeleteExpiredTelegramLinkCodes :exec
+DELETE FROM telegram_link_codes WHERE expires_at <= ?;
+--
+
+-- name: UpsertTelegramLink :exec
+INSERT INTO telegram_links (chat_id, created_at, user_id)
+VALUES (?, ?, ?)
+ON CONFLICT (chat_id) DO UPDATE SET created_at = excluded.created_at, user_id = excluded.user_id;
+--
+
+-- name: GetTelegramLink :one
+SELECT * FROM telegram_links WHERE chat_id = ?;
+--
+
+-- name: DeleteTelegramLink :exec
+DELETE FROM telegram_links WHERE chat_id = ?;
+--
@@ -0,0 +1,12 @@
+-- name: CreateTemplate :exec
+INSERT INTO templates (id, created_at, updated_at, name, body, user_id)
+VALUES (?, ?, ?, ?, ?, ?);
+--
+
+-- name: GetTemplate :one
+SELECT * FROM templates WHERE id = ?;
+--
+
+-- name: GetTemplatesForUser :many
+SELECT * FROM templates WHERE user_id = ? ORDER BY name;
+--
@@ -0,0 +1,13 @@
+-- name: CreateThrottleExemption :exec
+INSERT INTO throttle_exemptions (user_id, created_at)
+VALUES (?, ?)
+ON CONFLICT (user_id) DO NOTHING;
+--
+
+-- name: HasThrottleExemption :one
+SELECT EXISTS (SELECT 1 FROM throttle_exemptions WHERE user_id = ?) AS exempt;
+--
+
+-- name: DeleteThrottleExemption :execrows
+DELETE FROM throttle_exemptions WHERE user_id = ?;
+--
@@ -0,0 +1,28 @@
+-- name: CreateUsageAlert :exec
+INSERT INTO usage_alerts (id, created_at, user_id, metric, threshold)
+VALUES (?, ?, ?, ?, ?);
+--
+
+-- name: GetUsageAlert :one
+SELECT * FROM usage_alerts WHERE id = ?;
+--
+
+-- name: GetUsageAlertsForUser :many
+SELECT * FROM usage_alerts WHERE user_id = ? ORDER BY created_at;
+--
+
+-- name: GetUsageAlerts :many
+SELECT * FROM usage_alerts ORDER BY id;
+--
+
+-- name: CountUsageAlertsForUser :one
+SELECT COUNT(*) FROM usage_alerts WHERE user_id = ?;
+--
+
+-- name: SetUsageAlertTriggeredAt :exec
+UPDATE usage_alerts SET triggered_at = ? WHERE id = ?;
+--
+
+-- name: DeleteUsageAlertForUser :execrows
+DELETE FROM usage_alerts WHERE id = ? AND user_id = ?;
+--
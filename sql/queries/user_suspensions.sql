@@ -0,0 +1,13 @@
+-- name: CreateUserSuspension :execrows
+INSERT INTO user_suspensions (user_id, suspended_at, reason)
+VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO NOTHING;
+--
+
+-- name: GetUserSuspension :one
+SELECT * FROM user_suspensions WHERE user_id = ?;
+--
+
+-- name: DeleteUserSuspension :execrows
+DELETE FROM user_suspensions WHERE user_id = ?;
+--
@@ -12,3 +12,19 @@ VALUES (
 -- name: GetUser :one
 SELECT * FROM users WHERE api_key = ?;
 --
+
+-- name: UpdateUserAPIKey :execrows
+UPDATE users SET api_key = ?, updated_at = ? WHERE id = ?;
+--
+
+-- name: GetUsersByAPIKeyPrefix :many
+SELECT * FROM users WHERE api_key LIKE ?;
+--
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = ?;
+--
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = ?;
+--
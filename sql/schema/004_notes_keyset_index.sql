@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX notes_user_id_created_at_id_idx ON notes (user_id, created_at, id);
+
+-- +goose Down
+DROP INDEX notes_user_id_created_at_id_idx;
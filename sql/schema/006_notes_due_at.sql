@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN due_at TEXT;
+ALTER TABLE notes ADD COLUMN reminded_at TEXT;
+
+-- +goose Down
+ALTER TABLE notes DROP COLUMN reminded_at;
+ALTER TABLE notes DROP COLUMN due_at;
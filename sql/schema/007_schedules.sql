@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE schedules (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    spec TEXT NOT NULL,
+    template_values TEXT NOT NULL,
+    next_run_at TEXT NOT NULL,
+    template_id TEXT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX schedules_next_run_at_idx ON schedules (next_run_at);
+
+-- +goose Down
+DROP TABLE schedules;
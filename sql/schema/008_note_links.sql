@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE note_links (
+    source_note_id TEXT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    target_note_id TEXT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source_note_id, target_note_id)
+);
+
+CREATE INDEX note_links_target_note_id_idx ON note_links (target_note_id);
+
+-- +goose Down
+DROP TABLE note_links;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE comments (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    body TEXT NOT NULL,
+    note_id TEXT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX comments_note_id_created_at_idx ON comments (note_id, created_at);
+
+-- +goose Down
+DROP TABLE comments;
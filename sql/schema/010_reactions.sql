@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE reactions (
+    note_id TEXT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (note_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE reactions;
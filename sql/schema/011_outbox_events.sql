@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    sent_at TEXT
+);
+
+CREATE INDEX outbox_events_sent_at_id_idx ON outbox_events (sent_at, id);
+
+-- +goose Down
+DROP TABLE outbox_events;
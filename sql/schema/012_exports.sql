@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE exports (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    content TEXT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX exports_status_created_at_idx ON exports (status, created_at);
+
+-- +goose Down
+DROP TABLE exports;
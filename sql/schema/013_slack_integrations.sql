@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE slack_integrations (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    webhook_url TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX slack_integrations_user_id_idx ON slack_integrations (user_id);
+
+-- +goose Down
+DROP TABLE slack_integrations;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE telegram_link_codes (
+    code TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE telegram_links (
+    chat_id INTEGER PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE telegram_links;
+DROP TABLE telegram_link_codes;
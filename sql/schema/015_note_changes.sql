@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE note_changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    changed_at TEXT NOT NULL,
+    note_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX note_changes_user_id_seq_idx ON note_changes (user_id, seq);
+
+-- Triggers rather than application code, so that every path that touches
+-- notes (including bulk and cascading deletes) leaves a change record.
+
+-- +goose StatementBegin
+CREATE TRIGGER notes_insert_change AFTER INSERT ON notes
+BEGIN
+    INSERT INTO note_changes (changed_at, note_id, user_id)
+    VALUES (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), NEW.id, NEW.user_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER notes_update_change AFTER UPDATE ON notes
+BEGIN
+    INSERT INTO note_changes (changed_at, note_id, user_id)
+    VALUES (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), NEW.id, NEW.user_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER notes_delete_change AFTER DELETE ON notes
+BEGIN
+    INSERT INTO note_changes (changed_at, note_id, user_id, deleted)
+    VALUES (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), OLD.id, OLD.user_id, 1);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER notes_delete_change;
+DROP TRIGGER notes_update_change;
+DROP TRIGGER notes_insert_change;
+DROP TABLE note_changes;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE moderation_flags (
+    note_id TEXT PRIMARY KEY REFERENCES notes(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL,
+    reason TEXT NOT NULL
+);
+
+CREATE INDEX moderation_flags_created_at_idx ON moderation_flags (created_at);
+
+-- +goose Down
+DROP TABLE moderation_flags;
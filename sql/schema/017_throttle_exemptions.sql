@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE throttle_exemptions (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE throttle_exemptions;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE exports ADD COLUMN kind TEXT NOT NULL DEFAULT 'notes';
+
+CREATE TABLE erasure_requests (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL,
+    confirmation_token TEXT NOT NULL,
+    confirmed_at TEXT,
+    erase_after TEXT
+);
+
+CREATE INDEX erasure_requests_erase_after_idx ON erasure_requests (erase_after);
+
+-- +goose Down
+DROP TABLE erasure_requests;
+ALTER TABLE exports DROP COLUMN kind;
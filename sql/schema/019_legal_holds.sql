@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE legal_holds (
+    subject_type TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    PRIMARY KEY (subject_type, subject_id)
+);
+
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    details TEXT NOT NULL
+);
+
+CREATE INDEX audit_log_subject_idx ON audit_log (subject_type, subject_id);
+
+-- +goose Down
+DROP TABLE audit_log;
+DROP TABLE legal_holds;
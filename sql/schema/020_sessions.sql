@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE sessions (
+    token_hash TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_expires_at_idx ON sessions (expires_at);
+
+-- +goose Down
+DROP TABLE sessions;
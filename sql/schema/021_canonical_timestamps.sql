@@ -0,0 +1,119 @@
+-- +goose Up
+-- Rewrite timestamps written by other SQLite clients (for example
+-- "2023-01-01 00:00:00" or with a zone offset) as UTC RFC3339, the format the
+-- server writes, so that string comparisons in queries order them correctly.
+-- Values SQLite can't parse are left alone.
+
+UPDATE users SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE notes SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at),
+    due_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', due_at), due_at),
+    reminded_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', reminded_at), reminded_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+   OR due_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', due_at), due_at)
+   OR reminded_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', reminded_at), reminded_at);
+
+UPDATE settings SET
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE templates SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE schedules SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at),
+    next_run_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', next_run_at), next_run_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+   OR next_run_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', next_run_at), next_run_at);
+
+UPDATE note_links SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE comments SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE reactions SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE outbox_events SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    sent_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', sent_at), sent_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR sent_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', sent_at), sent_at);
+
+UPDATE exports SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE slack_integrations SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR updated_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE telegram_link_codes SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    expires_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', expires_at), expires_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR expires_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', expires_at), expires_at);
+
+UPDATE telegram_links SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE note_changes SET
+    changed_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', changed_at), changed_at)
+WHERE changed_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', changed_at), changed_at);
+
+UPDATE moderation_flags SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE throttle_exemptions SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE erasure_requests SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    confirmed_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', confirmed_at), confirmed_at),
+    erase_after = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', erase_after), erase_after)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR confirmed_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', confirmed_at), confirmed_at)
+   OR erase_after IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', erase_after), erase_after);
+
+UPDATE legal_holds SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE audit_log SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at);
+
+UPDATE sessions SET
+    created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+    expires_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', expires_at), expires_at)
+WHERE created_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at)
+   OR expires_at IS NOT COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', expires_at), expires_at);
+
+-- +goose Down
+-- Canonical timestamps are still accepted in every format, so there is
+-- nothing to undo.
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN slug TEXT;
+
+CREATE UNIQUE INDEX notes_slug_idx ON notes (slug);
+
+-- +goose Down
+DROP INDEX notes_slug_idx;
+ALTER TABLE notes DROP COLUMN slug;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE api_usage (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day TEXT NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE INDEX api_usage_day_idx ON api_usage (day);
+
+-- +goose Down
+DROP TABLE api_usage;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE plans (
+    name TEXT PRIMARY KEY,
+    notes_limit INTEGER NOT NULL,
+    requests_per_minute INTEGER NOT NULL
+);
+
+-- A limit of 0 means unlimited.
+INSERT INTO plans (name, notes_limit, requests_per_minute) VALUES
+    ('free', 1000, 60),
+    ('pro', 0, 600);
+
+-- Users without a row are on the free plan.
+CREATE TABLE user_plans (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plan TEXT NOT NULL REFERENCES plans(name),
+    stripe_customer_id TEXT UNIQUE,
+    updated_at TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE user_plans;
+DROP TABLE plans;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE user_plans ADD COLUMN bonus_notes INTEGER NOT NULL DEFAULT 0;
+
+-- A max_uses of 0 means unlimited.
+CREATE TABLE promo_codes (
+    code TEXT PRIMARY KEY,
+    plan TEXT REFERENCES plans(name),
+    bonus_notes INTEGER NOT NULL DEFAULT 0,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE promo_redemptions (
+    code TEXT NOT NULL REFERENCES promo_codes(code) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redeemed_at TEXT NOT NULL,
+    PRIMARY KEY (code, user_id)
+);
+
+-- +goose Down
+DROP TABLE promo_redemptions;
+DROP TABLE promo_codes;
+ALTER TABLE user_plans DROP COLUMN bonus_notes;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE usage_alerts (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric TEXT NOT NULL,
+    threshold INTEGER NOT NULL,
+    -- Set when the alert fires and cleared when it re-arms.
+    triggered_at TEXT
+);
+
+CREATE INDEX usage_alerts_user_id_idx ON usage_alerts (user_id);
+
+-- +goose Down
+DROP TABLE usage_alerts;
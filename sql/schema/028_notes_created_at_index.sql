@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX notes_created_at_idx ON notes (created_at);
+
+-- +goose Down
+DROP INDEX notes_created_at_idx;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE impersonation_tokens (
+    token_hash TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    allow_writes BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX impersonation_tokens_expires_at_idx ON impersonation_tokens (expires_at);
+
+-- +goose Down
+DROP TABLE impersonation_tokens;
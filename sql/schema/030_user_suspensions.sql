@@ -0,0 +1,10 @@
+-- +goose Up
+-- A user is suspended while they have a row here.
+CREATE TABLE user_suspensions (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    suspended_at TEXT NOT NULL,
+    reason TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE user_suspensions;
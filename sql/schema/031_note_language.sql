@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN language TEXT;
+
+CREATE INDEX notes_user_language_idx ON notes (user_id, language);
+
+-- +goose Down
+DROP INDEX notes_user_language_idx;
+ALTER TABLE notes DROP COLUMN language;
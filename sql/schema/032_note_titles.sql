@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN title TEXT;
+ALTER TABLE notes ADD COLUMN title_custom BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE notes DROP COLUMN title_custom;
+ALTER TABLE notes DROP COLUMN title;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN latitude REAL CHECK (latitude BETWEEN -90 AND 90);
+ALTER TABLE notes ADD COLUMN longitude REAL CHECK (longitude BETWEEN -180 AND 180);
+
+CREATE INDEX notes_user_latitude_idx ON notes (user_id, latitude);
+
+-- +goose Down
+DROP INDEX notes_user_latitude_idx;
+ALTER TABLE notes DROP COLUMN longitude;
+ALTER TABLE notes DROP COLUMN latitude;
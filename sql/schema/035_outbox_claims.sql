@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE outbox_events ADD COLUMN claimed_until TEXT;
+ALTER TABLE outbox_events ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE outbox_events DROP COLUMN attempts;
+ALTER TABLE outbox_events DROP COLUMN claimed_until;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE notes ADD COLUMN property_project TEXT
+    GENERATED ALWAYS AS (json_extract(properties, '$."project"')) VIRTUAL;
+
+CREATE INDEX notes_user_property_project_idx ON notes (user_id, property_project);
+
+-- +goose Down
+DROP INDEX notes_user_property_project_idx;
+ALTER TABLE notes DROP COLUMN property_project;
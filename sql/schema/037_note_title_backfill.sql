@@ -0,0 +1,7 @@
+-- +goose Up
+-- Lets the title backfill find the notes it still has to do without
+-- scanning the table; once it's done, the index is empty.
+CREATE INDEX notes_without_title_idx ON notes (id) WHERE title IS NULL;
+
+-- +goose Down
+DROP INDEX notes_without_title_idx;
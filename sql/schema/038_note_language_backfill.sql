@@ -0,0 +1,8 @@
+-- +goose Up
+-- notes_user_language_idx can't serve language IS NULL across all users, so
+-- the language backfill gets a partial index of its own; once it's done, the
+-- index is empty.
+CREATE INDEX notes_without_language_idx ON notes (id) WHERE language IS NULL;
+
+-- +goose Down
+DROP INDEX notes_without_language_idx;
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	// maxStaleAge bounds how out of date a stale read can be, which also
+	// bounds how long a revoked API key keeps working during an outage.
+	// Older entries are useless, so they are swept out this often too.
+	maxStaleAge = 5 * time.Minute
+	// maxStaleEntries and maxStaleBytes bound each staleCache. When a new
+	// entry doesn't fit even after a sweep, the cache is dropped and starts
+	// over.
+	maxStaleEntries = 100_000
+	maxStaleBytes   = 64 << 20
+	// maxStaleValueBytes is the largest value a staleCache keeps, so that a
+	// few large accounts can't take up the whole cache.
+	maxStaleValueBytes = 1 << 20
+)
+
+type staleEntry[T any] struct {
+	val   T
+	at    time.Time
+	bytes int
+}
+
+// staleCache remembers the last result of a read so it can still be served
+// while the circuit breaker keeps the database unavailable.
+type staleCache[T any] struct {
+	// weigh returns the approximate size of a value in bytes. Without it,
+	// only the number of entries is bounded.
+	weigh func(T) int
+
+	mu      sync.Mutex
+	entries map[string]staleEntry[T]
+	bytes   int
+	swept   time.Time
+}
+
+// read calls fn and remembers its result under key. If fn fails because the
+// database is unavailable and a result was remembered, that is returned
+// instead, with stale true.
+func (c *staleCache[T]) read(key string, fn func() (T, error)) (val T, stale bool, err error) {
+	val, err = fn()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.store(key, val, time.Now())
+		return val, false, nil
+	}
+	if errors.Is(err, errDBUnavailable) {
+		if cached, ok := c.entries[key]; ok && time.Since(cached.at) < maxStaleAge {
+			return cached.val, true, nil
+		}
+	}
+	return val, false, err
+}
+
+// store remembers val under key. c.mu must be held.
+func (c *staleCache[T]) store(key string, val T, now time.Time) {
+	c.remove(key)
+	size := 0
+	if c.weigh != nil {
+		size = c.weigh(val)
+	}
+	if size > maxStaleValueBytes {
+		return
+	}
+
+	full := func() bool {
+		return len(c.entries) >= maxStaleEntries || c.bytes+size > maxStaleBytes
+	}
+	if now.Sub(c.swept) >= maxStaleAge || full() {
+		c.sweep(now)
+	}
+	if c.entries == nil || full() {
+		c.entries = map[string]staleEntry[T]{}
+		c.bytes = 0
+	}
+	c.entries[key] = staleEntry[T]{val: val, at: now, bytes: size}
+	c.bytes += size
+}
+
+// sweep removes the entries too old to be served. c.mu must be held.
+func (c *staleCache[T]) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if now.Sub(entry.at) >= maxStaleAge {
+			c.remove(key)
+		}
+	}
+	c.swept = now
+}
+
+// remove drops key's entry, if any. c.mu must be held.
+func (c *staleCache[T]) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.bytes -= entry.bytes
+		delete(c.entries, key)
+	}
+}
+
+// forget drops what is remembered under key, for data that must not be
+// served any more, such as an erased account's.
+func (c *staleCache[T]) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+// noteListBytes estimates the memory taken by notes, for weighing cached
+// note lists.
+func noteListBytes(notes []database.Note) int {
+	const perNote = 256 // IDs, timestamps and other fixed-size fields
+	size := 0
+	for _, note := range notes {
+		size += perNote + len(note.Note) + len(note.Title.String) + len(note.Properties)
+	}
+	return size
+}
+
+// markStale tells clients the response was served from memory while the
+// database was unavailable.
+func markStale(w http.ResponseWriter) {
+	w.Header().Set("Warning", `110 - "Response is Stale"`)
+}
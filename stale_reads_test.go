@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleCacheSweepsExpiredEntries(t *testing.T) {
+	var c staleCache[string]
+	start := time.Now()
+	c.store("old", "a", start)
+	c.store("new", "b", start.Add(maxStaleAge-time.Second))
+	c.store("newer", "c", start.Add(maxStaleAge))
+
+	if _, ok := c.entries["old"]; ok {
+		t.Error("entry older than maxStaleAge wasn't swept")
+	}
+	if len(c.entries) != 2 {
+		t.Errorf("%d entries left, want 2", len(c.entries))
+	}
+}
+
+func TestStaleCacheBoundsBytes(t *testing.T) {
+	c := staleCache[string]{weigh: func(s string) int { return len(s) }}
+	now := time.Now()
+
+	c.store("huge", strings.Repeat("x", maxStaleValueBytes+1), now)
+	if len(c.entries) != 0 || c.bytes != 0 {
+		t.Errorf("value over maxStaleValueBytes was cached: %d entries, %d bytes", len(c.entries), c.bytes)
+	}
+
+	value := strings.Repeat("x", maxStaleValueBytes)
+	for i := 0; i < maxStaleBytes/maxStaleValueBytes+1; i++ {
+		c.store(strings.Repeat("k", i+1), value, now)
+		if c.bytes > maxStaleBytes {
+			t.Fatalf("cache holds %d bytes, over maxStaleBytes", c.bytes)
+		}
+	}
+
+	c.store("k", "replaced", now)
+	c.forget("k")
+	want := 0
+	for _, entry := range c.entries {
+		want += entry.bytes
+	}
+	if c.bytes != want {
+		t.Errorf("cache counts %d bytes, entries hold %d", c.bytes, want)
+	}
+}
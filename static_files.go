@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// hashedAsset matches file names with a content hash, like app.3f9c1a2b.js,
+// which can be cached forever because a new build gets a new name.
+var hashedAsset = regexp.MustCompile(`\.[0-9a-fA-F]{8,}\.[a-z0-9]+$`)
+
+// staticHandler serves the frontend from fsys. Paths that don't match a file
+// get index.html so client-side routes survive a reload, unless they look
+// like an asset or an API call, which get a real 404.
+func staticHandler(fsys fs.FS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		info, err := fs.Stat(fsys, name)
+		if err == nil && !info.IsDir() {
+			if hashedAsset.MatchString(name) {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			} else {
+				w.Header().Set("Cache-Control", "no-cache")
+			}
+			http.ServeFileFS(w, r, fsys, name)
+			return
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if path.Ext(name) != "" || name == "v1" || strings.HasPrefix(name, "v1/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFileFS(w, r, fsys, "index.html")
+	})
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// staticJSON serves a JSON body that was encoded once, up front.
+type staticJSON []byte
+
+func mustStaticJSON(payload any) staticJSON {
+	dat, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return staticJSON(dat)
+}
+
+func (s staticJSON) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(s)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+type suspensionStatus struct {
+	suspension database.UserSuspension
+	suspended  bool
+}
+
+// suspensionFor returns userID's suspension, or ok false if they aren't
+// suspended.
+func (cfg *apiConfig) suspensionFor(ctx context.Context, userID string) (suspension database.UserSuspension, ok bool, err error) {
+	status, _, err := cfg.staleSuspensions.read(userID, func() (suspensionStatus, error) {
+		suspension, err := cfg.DB.GetUserSuspension(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return suspensionStatus{}, nil
+		}
+		if err != nil {
+			return suspensionStatus{}, err
+		}
+		return suspensionStatus{suspension: suspension, suspended: true}, nil
+	})
+	return status.suspension, status.suspended, err
+}
+
+// allowUnsuspended responds with 403 and the reason if userID is suspended.
+// Suspended users' API keys still authenticate, so they learn why they've
+// been shut out rather than seeing their key rejected.
+func (cfg *apiConfig) allowUnsuspended(w http.ResponseWriter, r *http.Request, userID string) bool {
+	suspension, suspended, err := cfg.suspensionFor(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "check_account_status_failed", err)
+		return false
+	}
+	if suspended {
+		respondWithErrorDetail(w, http.StatusForbidden, "account_suspended", suspension.Reason, nil)
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) handlerSuspensionGet(w http.ResponseWriter, r *http.Request) {
+	suspension, suspended, err := cfg.suspensionFor(r.Context(), chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_suspension_failed", err)
+		return
+	}
+
+	type response struct {
+		IsSuspended bool       `json:"is_suspended"`
+		SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+		Reason      string     `json:"reason,omitempty"`
+	}
+	if !suspended {
+		respondWithJSON(w, http.StatusOK, response{})
+		return
+	}
+	suspendedAt, err := parseDBTime(suspension.SuspendedAt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "parse_suspended_date_failed", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		IsSuspended: true,
+		SuspendedAt: &suspendedAt,
+		Reason:      suspension.Reason,
+	})
+}
+
+type suspensionParameters struct {
+	Reason string `json:"reason"`
+}
+
+func decodeSuspensionParameters(w http.ResponseWriter, r *http.Request) (suspensionParameters, bool) {
+	decoder := json.NewDecoder(r.Body)
+	params := suspensionParameters{}
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return params, false
+	}
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "reason_is_required", nil)
+		return params, false
+	}
+	return params, true
+}
+
+// handlerSuspensionCreate suspends a user. The reason is shown to the user
+// on every request until they're reactivated.
+func (cfg *apiConfig) handlerSuspensionCreate(w http.ResponseWriter, r *http.Request) {
+	params, ok := decodeSuspensionParameters(w, r)
+	if !ok {
+		return
+	}
+	user, err := cfg.DB.GetUserByID(r.Context(), chi.URLParam(r, "userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user_not_found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_user_failed", err)
+		return
+	}
+
+	var created bool
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.CreateUserSuspension(r.Context(), database.CreateUserSuspensionParams{
+			UserID:      user.ID,
+			SuspendedAt: time.Now().UTC().Format(time.RFC3339),
+			Reason:      params.Reason,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		created = true
+		return recordAudit(r.Context(), q, auditActorAdmin, "user.suspended", "user", user.ID, params)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "suspend_user_failed", err)
+		return
+	}
+
+	// A suspended user can't read their notes, so there's no reason to keep
+	// them in memory.
+	cfg.staleNoteLists.forget(user.ID)
+
+	if !created {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// handlerSuspensionDelete reactivates a suspended user. Like suspending, it
+// needs a reason for the audit log.
+func (cfg *apiConfig) handlerSuspensionDelete(w http.ResponseWriter, r *http.Request) {
+	params, ok := decodeSuspensionParameters(w, r)
+	if !ok {
+		return
+	}
+	userID := chi.URLParam(r, "userID")
+
+	var found bool
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.DeleteUserSuspension(r.Context(), userID)
+		if err != nil || n == 0 {
+			return err
+		}
+		found = true
+		return recordAudit(r.Context(), q, auditActorAdmin, "user.reactivated", "user", userID, params)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "reactivate_user_failed", err)
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "user_not_suspended", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// dbTimeLayouts are the timestamp formats accepted from the database. The
+// server writes RFC3339, but rows written by other SQLite clients (the Turso
+// shell, CURRENT_TIMESTAMP defaults) use a space instead of the T and often
+// have no zone. Fractional seconds are optional in all of them. More can be
+// added with DB_TIME_LAYOUTS.
+var dbTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseDBTime parses a timestamp read from the database and converts it to
+// UTC, so API responses are consistent whatever was stored. Timestamps
+// without a zone are UTC, as SQLite's date functions produce.
+func parseDBTime(s string) (time.Time, error) {
+	for _, layout := range dbTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
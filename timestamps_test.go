@@ -0,0 +1,143 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+// instant is a random time with nanoseconds, in a random zone, for
+// testing/quick.
+type instant struct {
+	time.Time
+}
+
+var testZones = []*time.Location{
+	time.UTC,
+	time.FixedZone("", 0),
+	time.FixedZone("IST", 5*3600+30*60),
+	time.FixedZone("NPT", 5*3600+45*60),
+	time.FixedZone("PST", -8*3600),
+	time.FixedZone("LINT", 14*3600),
+	time.FixedZone("BIT", -12*3600),
+}
+
+func (instant) Generate(rand *rand.Rand, _ int) reflect.Value {
+	// Between 1970 and 2200.
+	sec := rand.Int63n(7258118400)
+	var nsec int64
+	switch rand.Intn(4) {
+	case 0: // whole seconds
+	case 1: // milliseconds, as JavaScript clients send
+		nsec = rand.Int63n(1000) * int64(time.Millisecond)
+	case 2: // microseconds
+		nsec = rand.Int63n(1_000_000) * int64(time.Microsecond)
+	default:
+		nsec = rand.Int63n(int64(time.Second))
+	}
+	t := time.Unix(sec, nsec).In(testZones[rand.Intn(len(testZones))])
+	return reflect.ValueOf(instant{t})
+}
+
+func TestParseDBTimeRoundTrip(t *testing.T) {
+	layouts := []struct {
+		name   string
+		layout string
+		// zoned layouts keep the zone; the others are written in UTC, as
+		// SQLite does.
+		zoned bool
+		// precision is what the layout keeps of the time.
+		precision time.Duration
+	}{
+		{name: "RFC3339", layout: time.RFC3339, zoned: true, precision: time.Second},
+		{name: "RFC3339Nano", layout: time.RFC3339Nano, zoned: true, precision: time.Nanosecond},
+		{name: "space with zone", layout: "2006-01-02 15:04:05.999999999Z07:00", zoned: true, precision: time.Nanosecond},
+		{name: "space", layout: "2006-01-02 15:04:05", precision: time.Second},
+		{name: "space with milliseconds", layout: "2006-01-02 15:04:05.000", precision: time.Millisecond},
+		{name: "space with fraction", layout: "2006-01-02 15:04:05.999999999", precision: time.Nanosecond},
+		{name: "T without zone", layout: "2006-01-02T15:04:05.999999999", precision: time.Nanosecond},
+	}
+	for _, l := range layouts {
+		t.Run(l.name, func(t *testing.T) {
+			roundTrips := func(in instant) bool {
+				written := in.Time
+				if !l.zoned {
+					written = written.UTC()
+				}
+				s := written.Format(l.layout)
+				got, err := parseDBTime(s)
+				if err != nil {
+					t.Logf("parseDBTime(%q): %v", s, err)
+					return false
+				}
+				want := in.Time.Truncate(l.precision)
+				if !got.Equal(want) || got.Location() != time.UTC {
+					t.Logf("parseDBTime(%q) = %v, want %v in UTC", s, got, want.UTC())
+					return false
+				}
+				return true
+			}
+			if err := quick.Check(roundTrips, &quick.Config{MaxCount: 2000}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseDBTimeRejects(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"yesterday",
+		"2024-02-30T00:00:00Z",
+		"2024-13-01 00:00:00",
+		"2024-01-01",
+		"1704067200",
+		// time.Time can't represent leap seconds, and SQLite never writes
+		// them.
+		"2016-12-31T23:59:60Z",
+		"2016-12-31 23:59:60",
+	} {
+		if got, err := parseDBTime(s); err == nil {
+			t.Errorf("parseDBTime(%q) = %v, want an error", s, got)
+		}
+	}
+}
+
+func TestDatabaseConvertersRoundTrip(t *testing.T) {
+	roundTrips := func(created, updated instant) bool {
+		user := database.User{
+			ID:        testUser.ID,
+			CreatedAt: created.Format(time.RFC3339),
+			UpdatedAt: updated.UTC().Format("2006-01-02 15:04:05"),
+		}
+		gotUser, err := databaseUserToUser(user)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+
+		note := testNote
+		note.CreatedAt = created.Format(time.RFC3339Nano)
+		note.UpdatedAt = updated.UTC().Format("2006-01-02 15:04:05.999999999")
+		note.DueAt.String, note.DueAt.Valid = created.Format("2006-01-02 15:04:05Z07:00"), true
+		gotNote, err := databaseNoteToNote(note)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+
+		return gotUser.CreatedAt.Equal(created.Truncate(time.Second)) &&
+			gotUser.UpdatedAt.Equal(updated.UTC().Truncate(time.Second)) &&
+			gotNote.CreatedAt.Equal(created.Time) &&
+			gotNote.UpdatedAt.Equal(updated.Time) &&
+			gotNote.DueAt.Equal(created.Truncate(time.Second)) &&
+			gotNote.CreatedAt.Location() == time.UTC
+	}
+	if err := quick.Check(roundTrips, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
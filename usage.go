@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+)
+
+const (
+	usageDayLayout  = "2006-01-02"
+	maxUsageDays    = 366
+	usageRollupSize = 100
+)
+
+type usageKey struct {
+	userID string
+	day    string
+}
+
+type usageCounts struct {
+	requests int64
+	bytes    int64
+}
+
+// usageTracker counts authenticated requests and response bytes per user
+// per UTC day in memory. flushUsage writes the counts to the database in
+// the background, so tracking costs no query per request.
+type usageTracker struct {
+	mu      sync.Mutex
+	pending map[usageKey]usageCounts
+}
+
+func (t *usageTracker) record(userID string, bytes int64) {
+	key := usageKey{userID: userID, day: time.Now().UTC().Format(usageDayLayout)}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending == nil {
+		t.pending = map[usageKey]usageCounts{}
+	}
+	counts := t.pending[key]
+	counts.requests++
+	counts.bytes += bytes
+	t.pending[key] = counts
+}
+
+// take returns the counts recorded since the last call and starts afresh.
+func (t *usageTracker) take() map[usageKey]usageCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending := t.pending
+	t.pending = nil
+	return pending
+}
+
+// restore adds counts that couldn't be written back for the next flush.
+func (t *usageTracker) restore(key usageKey, counts usageCounts) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending == nil {
+		t.pending = map[usageKey]usageCounts{}
+	}
+	c := t.pending[key]
+	c.requests += counts.requests
+	c.bytes += counts.bytes
+	t.pending[key] = c
+}
+
+// flushUsage adds the pending counts to the api_usage table. Counts are kept
+// for the next flush while the database is unavailable; any other failure,
+// such as the user having been erased meanwhile, drops them.
+func (cfg *apiConfig) flushUsage(ctx context.Context) error {
+	var lastErr error
+	for key, counts := range cfg.usage.take() {
+		err := cfg.DB.AddAPIUsage(ctx, database.AddAPIUsageParams{
+			UserID:   key.userID,
+			Day:      key.day,
+			Requests: counts.requests,
+			Bytes:    counts.bytes,
+		})
+		if err != nil {
+			if errors.Is(err, errDBUnavailable) {
+				cfg.usage.restore(key, counts)
+			}
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// byteCounter counts the bytes of a response body.
+type byteCounter struct {
+	http.ResponseWriter
+	bytes int64
+}
+
+func (c *byteCounter) Write(b []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(b)
+	c.bytes += int64(n)
+	return n, err
+}
+
+func (c *byteCounter) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *byteCounter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+type usageDay struct {
+	Day      string `json:"day"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// handlerUsageGet reports the user's API usage per day for the last ?days=
+// days (default 30), oldest first. Counts lag by up to a minute.
+func (cfg *apiConfig) handlerUsageGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	days := 30
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxUsageDays {
+			respondWithErrorDetail(w, http.StatusBadRequest, "invalid_days", strconv.Itoa(maxUsageDays), err)
+			return
+		}
+		days = n
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format(usageDayLayout)
+	usage, err := cfg.DB.GetAPIUsageForUser(r.Context(), database.GetAPIUsageForUserParams{
+		UserID: user.ID,
+		Day:    since,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_usage_failed", err)
+		return
+	}
+
+	resp := make([]usageDay, len(usage))
+	for i, u := range usage {
+		resp[i] = usageDay{Day: u.Day, Requests: u.Requests, Bytes: u.Bytes}
+	}
+	respondWithFields(w, r, http.StatusOK, resp)
+}
+
+// handlerUsageRollupGet totals usage per user between ?from= and ?to=
+// (inclusive UTC dates, defaulting to the current month), heaviest users
+// first.
+func (cfg *apiConfig) handlerUsageRollupGet(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, 1-now.Day()).Format(usageDayLayout)
+	to := now.Format(usageDayLayout)
+	for _, param := range []struct {
+		name, code string
+		value      *string
+	}{
+		{name: "from", code: "invalid_from_date", value: &from},
+		{name: "to", code: "invalid_to_date", value: &to},
+	} {
+		s := r.URL.Query().Get(param.name)
+		if s == "" {
+			continue
+		}
+		if _, err := time.Parse(usageDayLayout, s); err != nil {
+			respondWithError(w, http.StatusBadRequest, param.code, err)
+			return
+		}
+		*param.value = s
+	}
+
+	rows, err := cfg.DB.GetAPIUsageByUser(r.Context(), database.GetAPIUsageByUserParams{
+		Day:   from,
+		Day_2: to,
+		Limit: usageRollupSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_usage_failed", err)
+		return
+	}
+
+	type userUsage struct {
+		UserID   string `json:"user_id"`
+		Requests int64  `json:"requests"`
+		Bytes    int64  `json:"bytes"`
+	}
+	type response struct {
+		From  string      `json:"from"`
+		To    string      `json:"to"`
+		Users []userUsage `json:"users"`
+	}
+	resp := response{From: from, To: to, Users: make([]userUsage, len(rows))}
+	for i, row := range rows {
+		resp.Users[i] = userUsage{UserID: row.UserID, Requests: row.Requests, Bytes: row.Bytes}
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/bootdotdev/learn-cicd-starter/internal/database"
+	"github.com/bootdotdev/learn-cicd-starter/internal/events"
+)
+
+const (
+	// usageAlertNotesQuota fires when the user's notes reach threshold
+	// percent of their plan's note limit, and re-arms once they drop below.
+	usageAlertNotesQuota = "notes_quota_percent"
+	// usageAlertRequestsPerDay fires at most once per UTC day, when the
+	// day's API requests reach threshold.
+	usageAlertRequestsPerDay = "requests_per_day"
+
+	maxUsageAlertsPerUser = 20
+	usageAlertInterval    = 5 * time.Minute
+)
+
+type usageAlert struct {
+	ID          string     `json:"id"`
+	Metric      string     `json:"metric"`
+	Threshold   int64      `json:"threshold"`
+	TriggeredAt *time.Time `json:"triggered_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func databaseUsageAlertToUsageAlert(alert database.UsageAlert) (usageAlert, error) {
+	createdAt, err := parseDBTime(alert.CreatedAt)
+	if err != nil {
+		return usageAlert{}, err
+	}
+	var triggeredAt *time.Time
+	if alert.TriggeredAt.Valid {
+		t, err := parseDBTime(alert.TriggeredAt.String)
+		if err != nil {
+			return usageAlert{}, err
+		}
+		triggeredAt = &t
+	}
+	return usageAlert{
+		ID:          alert.ID,
+		Metric:      alert.Metric,
+		Threshold:   alert.Threshold,
+		TriggeredAt: triggeredAt,
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+func usageAlertLabel(alert database.UsageAlert) string {
+	switch alert.Metric {
+	case usageAlertNotesQuota:
+		return fmt.Sprintf("Your notes have reached %d%% of your plan's limit", alert.Threshold)
+	case usageAlertRequestsPerDay:
+		return fmt.Sprintf("You've made %d API requests today", alert.Threshold)
+	}
+	return alert.Metric
+}
+
+func (cfg *apiConfig) handlerUsageAlertsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	alerts, err := cfg.DB.GetUsageAlertsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_usage_alerts_failed", err)
+		return
+	}
+
+	resp := make([]usageAlert, len(alerts))
+	for i, alert := range alerts {
+		resp[i], err = databaseUsageAlertToUsageAlert(alert)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "convert_usage_alert_failed", err)
+			return
+		}
+	}
+	respondWithFields(w, r, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerUsageAlertsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Metric    string `json:"metric"`
+		Threshold int64  `json:"threshold"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "decode_parameters_failed", err)
+		return
+	}
+	switch params.Metric {
+	case usageAlertNotesQuota:
+		if params.Threshold < 1 || params.Threshold > 100 {
+			respondWithError(w, http.StatusBadRequest, "invalid_quota_threshold", nil)
+			return
+		}
+	case usageAlertRequestsPerDay:
+		if params.Threshold < 1 {
+			respondWithError(w, http.StatusBadRequest, "invalid_threshold", nil)
+			return
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, "invalid_usage_alert_metric", nil)
+		return
+	}
+
+	count, err := cfg.DB.CountUsageAlertsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "count_usage_alerts_failed", err)
+		return
+	}
+	if count >= maxUsageAlertsPerUser {
+		respondWithErrorDetail(w, http.StatusConflict, "too_many_usage_alerts", strconv.Itoa(maxUsageAlertsPerUser), nil)
+		return
+	}
+
+	id := cfg.ids.New()
+	err = cfg.DB.CreateUsageAlert(r.Context(), database.CreateUsageAlertParams{
+		ID:        id,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		UserID:    user.ID,
+		Metric:    params.Metric,
+		Threshold: params.Threshold,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "create_usage_alert_failed", err)
+		return
+	}
+
+	alert, err := cfg.DB.GetUsageAlert(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "get_usage_alert_failed", err)
+		return
+	}
+	resp, err := databaseUsageAlertToUsageAlert(alert)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "convert_usage_alert_failed", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (cfg *apiConfig) handlerUsageAlertsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	n, err := cfg.DB.DeleteUsageAlertForUser(r.Context(), database.DeleteUsageAlertForUserParams{
+		ID:     chi.URLParam(r, "alertID"),
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "delete_usage_alert_failed", err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "usage_alert_not_found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkUsageAlerts compares every alert with its user's current usage and
+// fires a usage.alert event for those that have crossed their threshold.
+// Events go through the outbox like any other, so users receive them on the
+// Slack integrations and broker subjects they've set up for usage.alert.
+func (cfg *apiConfig) checkUsageAlerts(ctx context.Context) error {
+	alerts, err := cfg.DB.GetUsageAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	today := now.Format(usageDayLayout)
+	for _, alert := range alerts {
+		value, ok, err := cfg.usageAlertValue(ctx, alert, today)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		var fire, rearm bool
+		switch alert.Metric {
+		case usageAlertNotesQuota:
+			fire = value >= alert.Threshold && !alert.TriggeredAt.Valid
+			rearm = value < alert.Threshold && alert.TriggeredAt.Valid
+		case usageAlertRequestsPerDay:
+			firedToday := alert.TriggeredAt.Valid && alert.TriggeredAt.String[:len(usageDayLayout)] == today
+			fire = value >= alert.Threshold && !firedToday
+		}
+
+		switch {
+		case fire:
+			err = cfg.withTx(ctx, func(q *database.Queries) error {
+				err := q.SetUsageAlertTriggeredAt(ctx, database.SetUsageAlertTriggeredAtParams{
+					TriggeredAt: sql.NullString{String: now.Format(time.RFC3339), Valid: true},
+					ID:          alert.ID,
+				})
+				if err != nil {
+					return err
+				}
+				return enqueueEvent(ctx, q, events.New(events.UsageAlert, alert.UserID, alert.ID))
+			})
+		case rearm:
+			err = cfg.DB.SetUsageAlertTriggeredAt(ctx, database.SetUsageAlertTriggeredAtParams{
+				ID: alert.ID,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usageAlertValue returns the current value of alert's metric for its user.
+// ok is false when the metric doesn't apply, e.g. a quota alert for a plan
+// without a note limit or while plans aren't enforced.
+func (cfg *apiConfig) usageAlertValue(ctx context.Context, alert database.UsageAlert, today string) (value int64, ok bool, err error) {
+	switch alert.Metric {
+	case usageAlertNotesQuota:
+		if !cfg.enforcePlans {
+			return 0, false, nil
+		}
+		plan, err := cfg.DB.GetPlanForUser(ctx, alert.UserID)
+		if err != nil {
+			return 0, false, err
+		}
+		if plan.NotesLimit <= 0 {
+			return 0, false, nil
+		}
+		bonus, err := cfg.DB.GetBonusNotesForUser(ctx, alert.UserID)
+		if err != nil {
+			return 0, false, err
+		}
+		storage, err := cfg.DB.GetNotesStorageForUser(ctx, alert.UserID)
+		if err != nil {
+			return 0, false, err
+		}
+		return storage.NoteCount * 100 / (plan.NotesLimit + bonus), true, nil
+	case usageAlertRequestsPerDay:
+		usage, err := cfg.DB.GetAPIUsageForUser(ctx, database.GetAPIUsageForUserParams{
+			UserID: alert.UserID,
+			Day:    today,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		for _, u := range usage {
+			value += u.Requests
+		}
+		return value, true, nil
+	}
+	return 0, false, nil
+}